@@ -12,13 +12,16 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/huynchu/degree-planner-api/config"
+	"github.com/huynchu/degree-planner-api/internal/admin"
 	"github.com/huynchu/degree-planner-api/internal/auth"
 	"github.com/huynchu/degree-planner-api/internal/course"
 	"github.com/huynchu/degree-planner-api/internal/degree"
 	degreecsv "github.com/huynchu/degree-planner-api/internal/degree-csv"
+	"github.com/huynchu/degree-planner-api/internal/health"
 	mymiddleware "github.com/huynchu/degree-planner-api/internal/middleware"
 	"github.com/huynchu/degree-planner-api/internal/storage"
 	"github.com/huynchu/degree-planner-api/internal/user"
+	"github.com/huynchu/degree-planner-api/internal/workers"
 )
 
 func main() {
@@ -52,13 +55,85 @@ func main() {
 	}
 	s3Client := s3.NewFromConfig(cfg)
 
-	// Run course data worker
-	// courseDataWorker := workers.NewCourseDataWorker(db)
-	// go courseDataWorker.Run()
+	// Create course data worker
+	source, err := workers.NewCatalogSource(context.TODO(), env)
+	if err != nil {
+		fmt.Printf("error: %v", err)
+		exitCode = 1
+		return
+	}
+	adapter, err := workers.NewCatalogAdapter(env.CATALOG_FORMAT)
+	if err != nil {
+		fmt.Printf("error: %v", err)
+		exitCode = 1
+		return
+	}
+	courseDataWorker := workers.NewCourseDataWorker(db, source, adapter)
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
 
-	// Create Course dependencies
+	// Create course storage and the in memory course index, the index is
+	// refreshed after syncs of this replica and polled for syncs of others
 	courseStorage := course.NewCourseStorage(db)
-	courseService := course.NewCourseService(courseStorage)
+	err = courseStorage.EnsureIndexes(context.TODO())
+	if err != nil {
+		fmt.Printf("error: %v", err)
+		exitCode = 1
+		return
+	}
+	backfilled, err := courseStorage.BackfillSubjectAndLevel(context.TODO())
+	if err != nil {
+		fmt.Printf("error: %v", err)
+		exitCode = 1
+		return
+	}
+	if backfilled > 0 {
+		fmt.Println("backfilled subject and level of", backfilled, "courses")
+	}
+	courseIndex := course.NewCourseIndex(courseStorage)
+	err = courseIndex.Refresh()
+	if err != nil {
+		fmt.Printf("error: %v", err)
+		exitCode = 1
+		return
+	}
+	courseDataWorker.OnSync(func(run *workers.SyncRunDB) {
+		err := courseIndex.Refresh()
+		if err != nil {
+			fmt.Println("Error refreshing course index:", err)
+		}
+	})
+	syncRunStorage := workers.NewSyncRunStorage(db)
+	go courseIndex.Watch(workerCtx, time.Minute, func() (string, error) {
+		run, err := syncRunStorage.FindLastSyncedRun()
+		if err != nil || run == nil {
+			return "", err
+		}
+		return run.ID.Hex(), nil
+	})
+
+	// Run admin triggered sync jobs
+	jobStorage := workers.NewJobStorage(db)
+	jobPool := workers.NewJobPool(db, courseDataWorker, jobStorage, 2, 16, env.CATALOG_YEAR)
+	jobPool.Start(workerCtx)
+
+	// Schedule course data worker, only if a schedule is configured
+	var scheduler *workers.Scheduler
+	if env.CATALOG_SYNC_SCHEDULE != "" {
+		scheduler, err = workers.NewScheduler(db, courseDataWorker, env.CATALOG_SYNC_SCHEDULE, workers.RunOptions{
+			CatalogYear: env.CATALOG_YEAR,
+		})
+		if err != nil {
+			fmt.Printf("error: %v", err)
+			exitCode = 1
+			return
+		}
+		scheduler.Start(workerCtx)
+		fmt.Println("scheduled catalog sync:", env.CATALOG_SYNC_SCHEDULE)
+	}
+
+	// Create Course dependencies
+	courseService := course.NewCourseService(courseStorage, courseIndex)
 	courseController := course.NewCourseController(courseService)
 	// Create Degree dependencies
 	degreeStorage := degree.NewDegreeStorage(db)
@@ -69,9 +144,15 @@ func main() {
 	// Create User dependencies
 	userStorage := user.NewUserStorage(db)
 	userService := user.NewUserService(userStorage)
-	// userController := user.NewUserController(userService)
+	userController := user.NewUserController(userService)
 	// Create Auth dependencies
 	authController := auth.NewAuthController(userService)
+	// Create Admin dependencies
+	adminService := admin.NewAdminService(syncRunStorage, jobStorage, jobPool, courseDataWorker, courseStorage)
+	adminController := admin.NewAdminController(adminService)
+	// Create Health dependencies
+	healthService := health.NewHealthService(syncRunStorage, scheduler)
+	healthController := health.NewHealthController(healthService)
 
 	// create chi router
 	r := chi.NewRouter()
@@ -80,6 +161,7 @@ func main() {
 	// Public routes
 	r.Group(func(r chi.Router) {
 		r.Get("/health", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("welcome")) })
+		health.AddHealthRoutes(r, healthController)
 
 		r.Get("/api/auth/login/google", authController.HandleGoogleLogin)
 		r.HandleFunc("/api/auth/google/callback", authController.CallBackFromGoogle)
@@ -93,7 +175,16 @@ func main() {
 
 		degree.AddDegreeRoutes(r, degreeController)
 
+		user.AddUserRoutes(r, userController)
+
 		r.Post("/degree-csv", degreeCsvController.UploadDegreeCsv)
+
+		// Admin routes
+		r.Group(func(r chi.Router) {
+			r.Use(mymiddleware.RequireAdmin)
+
+			admin.AddAdminRoutes(r, adminController)
+		})
 	})
 
 	// auth0 endpoints
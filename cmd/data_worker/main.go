@@ -1,25 +1,155 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/huynchu/degree-planner-api/config"
+	"github.com/huynchu/degree-planner-api/internal/course"
 	"github.com/huynchu/degree-planner-api/internal/storage"
 	"github.com/huynchu/degree-planner-api/internal/workers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func main() {
+	var exitCode int
+	defer func() {
+		os.Exit(exitCode)
+	}()
+
+	// parse flags
+	dryRun := flag.Bool("dry-run", false, "compute the catalog changes without writing to mongodb")
+	report := flag.String("report", "text", "report format: text or json")
+	catalogYear := flag.String("catalog-year", "", "academic catalog year of the course data, i.e 2024-2025 (defaults to CATALOG_YEAR)")
+	force := flag.Bool("force", false, "sync even if the catalog source data did not change since the last run")
+	validate := flag.Bool("validate", false, "check the catalog source data for integrity issues without syncing")
+	flag.Parse()
+
+	if *report != "text" && *report != "json" {
+		fmt.Fprintf(os.Stderr, "error: invalid report format %q: must be text or json\n", *report)
+		exitCode = 2
+		return
+	}
+
+	// keep stdout for the json report
+	var out io.Writer = os.Stdout
+	if *report == "json" {
+		out = os.Stderr
+	}
+
 	// load config
 	env, err := config.LoadConfig()
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		exitCode = 1
+		return
+	}
+	if *catalogYear == "" {
+		*catalogYear = env.CATALOG_YEAR
 	}
+	if *catalogYear != "" && !course.IsValidCatalogYear(*catalogYear) {
+		fmt.Fprintf(os.Stderr, "error: invalid catalog year %q: must be formatted as 2024-2025\n", *catalogYear)
+		exitCode = 2
+		return
+	}
+
 	// connect to db
 	db, err := storage.BootstrapMongo(env.MONGODB_URI, env.MONGODB_NAME, 10*time.Second)
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		exitCode = 1
+		return
+	}
+	defer storage.CloseMongo(db)
+
+	// select catalog source
+	source, err := workers.NewCatalogSource(context.Background(), env)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		exitCode = 1
+		return
+	}
+
+	adapter, err := workers.NewCatalogAdapter(env.CATALOG_FORMAT)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		exitCode = 1
+		return
+	}
+
+	courseDataWorker := workers.NewCourseDataWorker(db, source, adapter)
+
+	// validate instead of syncing, issues exit with 1
+	if *validate {
+		validation, err := courseDataWorker.Validate(context.Background())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			exitCode = 1
+			return
+		}
+		if *report == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.Encode(validation)
+		} else {
+			validation.WriteText(out)
+		}
+		if validation.IssueCount() > 0 {
+			exitCode = 1
+		}
+		return
+	}
+
+	opts := workers.RunOptions{
+		DryRun:      *dryRun,
+		Out:         out,
+		CatalogYear: *catalogYear,
+		Force:       *force,
+		Trigger:     workers.SyncTriggerCLI,
+	}
+
+	// hold the catalog sync lock so the sync never overlaps the scheduler or
+	// an admin job, dry runs don't write and skip the lock
+	var run *workers.SyncRunDB
+	if *dryRun {
+		run, err = courseDataWorker.Run(context.Background(), opts)
+	} else {
+		hostname, _ := os.Hostname()
+		owner := "cli-" + hostname + "-" + primitive.NewObjectID().Hex()
+		lock := workers.NewLeaseLock(db, workers.CatalogSyncLockName, owner, workers.CatalogSyncLockTTL)
+		err = workers.WithLock(context.Background(), lock, func(ctx context.Context) error {
+			var runErr error
+			run, runErr = courseDataWorker.Run(ctx, opts)
+			return runErr
+		})
+	}
+	if errors.Is(err, workers.ErrLockHeld) {
+		fmt.Fprintln(os.Stderr, "error: another catalog sync is running, try again once it finished")
+		exitCode = 1
+		return
+	}
+	if run == nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		exitCode = 1
+		return
 	}
 
-	courseDataWorker := workers.NewCourseDataWorker(db)
-	courseDataWorker.Run()
+	if *report == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(run)
+	} else {
+		fmt.Fprintln(out, "Sync", run.Status, "in", run.FinishedAt.Sub(run.StartedAt))
+	}
+
+	if err != nil {
+		exitCode = 1
+		return
+	}
 }
@@ -21,6 +21,20 @@ type EnvVars struct {
 	// Quatalog course data urls
 	COURSE_DATA_URL        string `mapstructure:"COURSE_DATA_URL"`
 	COURSE_PREREQ_DATA_URL string `mapstructure:"COURSE_PREREQ_DATA_URL"`
+	// Academic catalog year of the course data, i.e 2024-2025
+	CATALOG_YEAR string `mapstructure:"CATALOG_YEAR"`
+
+	// Course data worker catalog source: file, http or s3, and the catalog
+	// format: quatalog, csv or yaml
+	CATALOG_SOURCE    string `mapstructure:"CATALOG_SOURCE"`
+	CATALOG_FORMAT    string `mapstructure:"CATALOG_FORMAT"`
+	CATALOG_DATA_DIR  string `mapstructure:"CATALOG_DATA_DIR"`
+	CATALOG_S3_BUCKET string `mapstructure:"CATALOG_S3_BUCKET"`
+	CATALOG_S3_PREFIX string `mapstructure:"CATALOG_S3_PREFIX"`
+
+	// In process catalog sync schedule, an interval i.e 24h or a cron
+	// expression i.e "0 3 * * *". Disabled if empty
+	CATALOG_SYNC_SCHEDULE string `mapstructure:"CATALOG_SYNC_SCHEDULE"`
 
 	// Auth0 config
 	AUTH0_DOMAIN   string `mapstructure:"AUTH0_DOMAIN"`
@@ -57,6 +71,13 @@ func LoadConfig() (config EnvVars, err error) {
 			PORT:                   os.Getenv("PORT"),
 			COURSE_DATA_URL:        os.Getenv("COURSE_DATA_URL"),
 			COURSE_PREREQ_DATA_URL: os.Getenv("COURSE_PREREQ_DATA_URL"),
+			CATALOG_YEAR:           os.Getenv("CATALOG_YEAR"),
+			CATALOG_SOURCE:         os.Getenv("CATALOG_SOURCE"),
+			CATALOG_FORMAT:         os.Getenv("CATALOG_FORMAT"),
+			CATALOG_DATA_DIR:       os.Getenv("CATALOG_DATA_DIR"),
+			CATALOG_S3_BUCKET:      os.Getenv("CATALOG_S3_BUCKET"),
+			CATALOG_S3_PREFIX:      os.Getenv("CATALOG_S3_PREFIX"),
+			CATALOG_SYNC_SCHEDULE:  os.Getenv("CATALOG_SYNC_SCHEDULE"),
 			AUTH0_DOMAIN:           os.Getenv("AUTH0_DOMAIN"),
 			AUTH0_AUDIENCE:         os.Getenv("AUTH0_AUDIENCE"),
 			GOOGLE_CLIENT_ID:       os.Getenv("GOOGLE_CLIENT_ID"),
@@ -0,0 +1,281 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"github.com/huynchu/degree-planner-api/internal/workers"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AdminController struct {
+	adminService *AdminService
+}
+
+func NewAdminController(asrv *AdminService) *AdminController {
+	return &AdminController{
+		adminService: asrv,
+	}
+}
+
+func (ac *AdminController) FindSyncRuns(w http.ResponseWriter, r *http.Request) {
+	// extract query params
+	maxLimit := 100
+	limit := 20
+	if limitQuery := r.URL.Query().Get("limit"); limitQuery != "" {
+		tmp, err := strconv.Atoi(limitQuery)
+		if err != nil || tmp <= 0 || tmp > maxLimit {
+			http.Error(w, fmt.Sprintf("invalid limit param: limit must be greater than 0 and less than %v", maxLimit), http.StatusBadRequest)
+			return
+		}
+		limit = tmp
+	}
+
+	// fetch sync runs
+	runs, err := ac.adminService.FindSyncRuns(limit)
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "database fetch error: fetch sync runs", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(runs)
+}
+
+func (ac *AdminController) EnqueueSync(w http.ResponseWriter, r *http.Request) {
+	// decode json body, an empty body syncs with the default options
+	var syncReq SyncRequest
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&syncReq)
+		if err != nil {
+			http.Error(w, "invalid json body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// enqueue sync job
+	job, err := ac.adminService.EnqueueSync(syncReq)
+	if err != nil {
+		if err == course.ErrInvalidCatalogYear {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == workers.ErrJobQueueFull {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database insert error: enqueue sync job", http.StatusInternalServerError)
+		return
+	}
+
+	// encode json response
+	res := struct {
+		ID string `json:"id"`
+	}{
+		ID: job.ID.Hex(),
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (ac *AdminController) FindJobByID(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	jobID := chi.URLParam(r, "jobID")
+
+	// fetch job from db
+	job, err := ac.adminService.FindJobByID(jobID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database fetch error: fetch job", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+func (ac *AdminController) CancelJob(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	jobID := chi.URLParam(r, "jobID")
+
+	// cancel job
+	err := ac.adminService.CancelJob(jobID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments || err == workers.ErrJobNotFound {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+		if err == workers.ErrJobNotCancelable {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database update error: cancel job", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode("cancel requested")
+}
+
+func (ac *AdminController) ValidateCatalog(w http.ResponseWriter, r *http.Request) {
+	// validate the catalog source data
+	report, err := ac.adminService.ValidateCatalog(r.Context())
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "catalog source error: validate catalog", http.StatusBadGateway)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+func (ac *AdminController) FindCourseOverrides(w http.ResponseWriter, r *http.Request) {
+	// fetch overrides
+	overrides, err := ac.adminService.FindCourseOverrides()
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "database fetch error: fetch course overrides", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(overrides)
+}
+
+func (ac *AdminController) FindCourseOverrideByID(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	overrideID := chi.URLParam(r, "overrideID")
+
+	// fetch override from db
+	override, err := ac.adminService.FindCourseOverrideByID(overrideID)
+	if err != nil {
+		if err == course.ErrCourseOverrideNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database fetch error: fetch course override", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(override)
+}
+
+func (ac *AdminController) CreateCourseOverride(w http.ResponseWriter, r *http.Request) {
+	// decode json body
+	var override course.CourseOverrideDB
+	err := json.NewDecoder(r.Body).Decode(&override)
+	if err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	// create override
+	id, err := ac.adminService.CreateCourseOverride(&override)
+	if err != nil {
+		if errors.Is(err, course.ErrInvalidCourseOverride) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database insert error: create course override", http.StatusInternalServerError)
+		return
+	}
+
+	// encode json response
+	res := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (ac *AdminController) ReplaceCourseOverride(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	overrideID := chi.URLParam(r, "overrideID")
+
+	// decode json body
+	var override course.CourseOverrideDB
+	err := json.NewDecoder(r.Body).Decode(&override)
+	if err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	// replace override
+	err = ac.adminService.ReplaceCourseOverride(overrideID, &override)
+	if err != nil {
+		if errors.Is(err, course.ErrInvalidCourseOverride) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == course.ErrCourseOverrideNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database update error: replace course override", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(override)
+}
+
+func (ac *AdminController) DeleteCourseOverride(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	overrideID := chi.URLParam(r, "overrideID")
+
+	// delete override
+	err := ac.adminService.DeleteCourseOverride(overrideID)
+	if err != nil {
+		if err == course.ErrCourseOverrideNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database delete error: delete course override", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode("course override deleted")
+}
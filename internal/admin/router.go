@@ -0,0 +1,22 @@
+package admin
+
+import "github.com/go-chi/chi/v5"
+
+func AddAdminRoutes(r chi.Router, controller *AdminController) {
+	// Catalog routes
+	r.Get("/api/admin/catalog/syncs", controller.FindSyncRuns)
+	r.Post("/api/admin/catalog/sync", controller.EnqueueSync)
+	r.Get("/api/admin/catalog/validate", controller.ValidateCatalog)
+
+	// Course override routes, overrides are merged over the catalog source data
+	// on the next sync
+	r.Get("/api/admin/course-overrides", controller.FindCourseOverrides)
+	r.Post("/api/admin/course-overrides", controller.CreateCourseOverride)
+	r.Get("/api/admin/course-overrides/{overrideID}", controller.FindCourseOverrideByID)
+	r.Put("/api/admin/course-overrides/{overrideID}", controller.ReplaceCourseOverride)
+	r.Delete("/api/admin/course-overrides/{overrideID}", controller.DeleteCourseOverride)
+
+	// Job routes
+	r.Get("/api/admin/jobs/{jobID}", controller.FindJobByID)
+	r.Post("/api/admin/jobs/{jobID}/cancel", controller.CancelJob)
+}
@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"github.com/huynchu/degree-planner-api/internal/workers"
+)
+
+type AdminService struct {
+	syncRunStorage *workers.SyncRunStorage
+	jobStorage     *workers.JobStorage
+	jobPool        *workers.JobPool
+	courseWorker   *workers.CourseDataWorker
+	courseStorage  *course.CourseStorage
+}
+
+func NewAdminService(srs *workers.SyncRunStorage, js *workers.JobStorage, jp *workers.JobPool, cdw *workers.CourseDataWorker, cs *course.CourseStorage) *AdminService {
+	return &AdminService{
+		syncRunStorage: srs,
+		jobStorage:     js,
+		jobPool:        jp,
+		courseWorker:   cdw,
+		courseStorage:  cs,
+	}
+}
+
+func (as *AdminService) FindSyncRuns(limit int) ([]workers.SyncRunDB, error) {
+	return as.syncRunStorage.FindSyncRuns(limit)
+}
+
+type SyncRequest struct {
+	DryRun      bool   `json:"dryRun"`
+	Force       bool   `json:"force"`
+	CatalogYear string `json:"catalogYear"`
+}
+
+func (as *AdminService) EnqueueSync(req SyncRequest) (*workers.JobDB, error) {
+	if req.CatalogYear != "" && !course.IsValidCatalogYear(req.CatalogYear) {
+		return nil, course.ErrInvalidCatalogYear
+	}
+	return as.jobPool.Enqueue(req.DryRun, req.Force, req.CatalogYear)
+}
+
+func (as *AdminService) FindJobByID(id string) (*workers.JobDB, error) {
+	return as.jobStorage.FindJobByID(id)
+}
+
+func (as *AdminService) CancelJob(id string) error {
+	if _, err := as.jobStorage.FindJobByID(id); err != nil {
+		return err
+	}
+	return as.jobPool.Cancel(id)
+}
+
+func (as *AdminService) ValidateCatalog(ctx context.Context) (*workers.ValidationReport, error) {
+	return as.courseWorker.Validate(ctx)
+}
+
+func (as *AdminService) FindCourseOverrides() ([]course.CourseOverrideDB, error) {
+	return as.courseStorage.FindCourseOverrides()
+}
+
+func (as *AdminService) FindCourseOverrideByID(id string) (*course.CourseOverrideDB, error) {
+	return as.courseStorage.FindCourseOverrideByID(id)
+}
+
+// CreateCourseOverride stores the override, it is merged over the source data
+// from the next sync on
+func (as *AdminService) CreateCourseOverride(override *course.CourseOverrideDB) (string, error) {
+	err := override.Validate()
+	if err != nil {
+		return "", err
+	}
+	override.UpdatedAt = time.Now()
+	return as.courseStorage.CreateCourseOverride(override)
+}
+
+func (as *AdminService) ReplaceCourseOverride(id string, override *course.CourseOverrideDB) error {
+	err := override.Validate()
+	if err != nil {
+		return err
+	}
+	override.UpdatedAt = time.Now()
+	return as.courseStorage.ReplaceCourseOverride(id, override)
+}
+
+func (as *AdminService) DeleteCourseOverride(id string) error {
+	return as.courseStorage.DeleteCourseOverride(id)
+}
@@ -0,0 +1,109 @@
+package course
+
+import (
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// A course suggested while typing a course code or name
+type AutocompleteSuggestion struct {
+	ID   primitive.ObjectID `json:"id"`
+	Code string             `json:"code"`
+	Name string             `json:"name"`
+}
+
+// Autocomplete suggests the current courses of the catalog year whose code or
+// name word starts with the prefix, code matches first
+func (ci *CourseIndex) Autocomplete(catalogYear string, prefix string, limit int) ([]AutocompleteSuggestion, error) {
+	snapshot, err := ci.snapshot(catalogYear)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []AutocompleteSuggestion{}
+	for _, i := range snapshot.prefixes.search(prefix, limit) {
+		c := &snapshot.courses[i]
+		suggestions = append(suggestions, AutocompleteSuggestion{ID: c.ID, Code: c.Code, Name: c.Name})
+	}
+	return suggestions, nil
+}
+
+type prefixEntry struct {
+	key    string
+	course int
+}
+
+// Sorted keys of the current courses, a prefix search is a binary search
+// followed by a scan of the matching keys
+type prefixIndex struct {
+	// Lowercase codes, i.e csci-1200
+	codes []prefixEntry
+	// Lowercase full names and name words, i.e "data structures" and "structures"
+	names []prefixEntry
+}
+
+func newPrefixIndex(courses []CourseDB) *prefixIndex {
+	index := &prefixIndex{}
+	for i := range courses {
+		if courses[i].Retired {
+			continue
+		}
+		index.codes = append(index.codes, prefixEntry{key: strings.ToLower(courses[i].Code), course: i})
+
+		name := strings.ToLower(strings.TrimSpace(courses[i].Name))
+		index.names = append(index.names, prefixEntry{key: name, course: i})
+		words := fuzzyWords(name)
+		for j := 1; j < len(words); j++ {
+			index.names = append(index.names, prefixEntry{key: words[j], course: i})
+		}
+	}
+	for _, entries := range [][]prefixEntry{index.codes, index.names} {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].key != entries[j].key {
+				return entries[i].key < entries[j].key
+			}
+			return entries[i].course < entries[j].course
+		})
+	}
+	return index
+}
+
+// search returns up to limit courses by index, i.e "csci 12" matches the code
+// CSCI-1200 and "struct" the name Data Structures
+func (index *prefixIndex) search(prefix string, limit int) []int {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || limit <= 0 {
+		return []int{}
+	}
+
+	res := []int{}
+	seen := make(map[int]bool)
+	codePrefix := prefix
+	if match := codeQueryPattern.FindStringSubmatch(prefix); match != nil {
+		codePrefix = match[1] + "-" + match[2]
+	}
+	for _, scan := range []struct {
+		entries []prefixEntry
+		prefix  string
+	}{
+		{index.codes, codePrefix},
+		{index.names, prefix},
+	} {
+		start := sort.Search(len(scan.entries), func(i int) bool {
+			return scan.entries[i].key >= scan.prefix
+		})
+		for i := start; i < len(scan.entries) && len(res) < limit; i++ {
+			entry := scan.entries[i]
+			if !strings.HasPrefix(entry.key, scan.prefix) {
+				break
+			}
+			if !seen[entry.course] {
+				seen[entry.course] = true
+				res = append(res, entry.course)
+			}
+		}
+	}
+	return res
+}
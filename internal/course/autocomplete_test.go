@@ -0,0 +1,75 @@
+package course
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCourseIndex__Autocomplete(t *testing.T) {
+	index := testCourseIndex()
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"CSCI-12", []string{"CSCI-1200"}},
+		{"csci 11", []string{"CSCI-1100"}},
+		{"csci11", []string{"CSCI-1100"}},
+		{"CSCI-1", []string{"CSCI-1100", "CSCI-1200"}},
+		{"struct", []string{"CSCI-1200"}},
+		{"data s", []string{"CSCI-1200"}},
+		// Code matches come before name matches
+		{"c", []string{"CSCI-1100", "CSCI-1200", "CSCI-2300", "CSCI-4020", "MATH-1010"}},
+		{"algo", []string{"CSCI-2300", "CSCI-4020"}},
+		// Retired courses are not suggested
+		{"beginning", []string{}},
+		{"zz", []string{}},
+	}
+
+	for _, test := range tests {
+		suggestions, err := index.Autocomplete("2024-2025", test.prefix, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes := []string{}
+		for _, s := range suggestions {
+			codes = append(codes, s.Code)
+		}
+		if fmt.Sprint(codes) != fmt.Sprint(test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.prefix, test.expected, codes)
+		}
+	}
+
+	suggestions, _ := index.Autocomplete("2024-2025", "c", 2)
+	if len(suggestions) != 2 {
+		t.Errorf("Expected 2 suggestions, got %v", suggestions)
+	}
+}
+
+func BenchmarkAutocomplete(b *testing.B) {
+	courses := make([]CourseDB, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		courses = append(courses, CourseDB{
+			Code: fmt.Sprintf("S%03d-%04d", i%500, 1000+i),
+			Name: fmt.Sprintf("Topics in Subject %v Part %v", i%500, i),
+		})
+	}
+	index := &CourseIndex{years: map[string]*catalogSnapshot{"": newCatalogSnapshot(courses)}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Autocomplete("", "topics", 10)
+	}
+}
+
+func TestCourseIndex__Autocomplete__UnknownCatalogYear(t *testing.T) {
+	index := testCourseIndex()
+
+	for _, year := range []string{"1999-2000", "2023-2024", ""} {
+		_, err := index.Autocomplete(year, "CSCI", 10)
+		if !errors.Is(err, ErrUnknownCatalogYear) {
+			t.Errorf("%q: expected ErrUnknownCatalogYear, got %v", year, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrInvalidCatalogYear = errors.New("invalid catalog year: must be formatted as 2024-2025")
+
+var catalogYearPattern = regexp.MustCompile(`^\d{4}-\d{4}$`)
+
+// IsValidCatalogYear reports whether year is an academic year, i.e 2024-2025
+func IsValidCatalogYear(year string) bool {
+	return catalogYearPattern.MatchString(year)
+}
+
+// CatalogYearFilter matches documents of the catalog year, the empty year
+// matches documents ingested before catalogs were versioned
+func CatalogYearFilter(year string) interface{} {
+	if year == "" {
+		return bson.M{"$in": bson.A{nil, ""}}
+	}
+	return year
+}
+
+// FindCatalogYears returns every ingested catalog year, oldest first
+func (s *CourseStorage) FindCatalogYears() ([]string, error) {
+	collection := s.db.Collection(COURSE_COLLECTION)
+
+	values, err := collection.Distinct(context.Background(), "catalogYear", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	years := []string{}
+	for _, v := range values {
+		if year, ok := v.(string); ok && year != "" {
+			years = append(years, year)
+		}
+	}
+	sort.Strings(years)
+
+	return years, nil
+}
@@ -0,0 +1,34 @@
+package course
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// A course code is a subject and a course number, i.e CSCI-1200
+var codePattern = regexp.MustCompile(`^([A-Z]{2,6})-(\d{3,4})([A-Z]?)$`)
+
+// ParseCode splits a course code into its subject and number, i.e
+// "CSCI-1200" -> CSCI, 1200. Returns false if the code does not parse
+func ParseCode(code string) (string, int, bool) {
+	match := codePattern.FindStringSubmatch(code)
+	if match == nil {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], number, true
+}
+
+// SubjectAndLevel returns the subject and level of a course code, i.e
+// "CSCI-4100" -> CSCI, 4000. Returns an empty subject and level 0 if the code
+// does not parse
+func SubjectAndLevel(code string) (string, int) {
+	subject, number, ok := ParseCode(code)
+	if !ok {
+		return "", 0
+	}
+	return subject, number / 1000 * 1000
+}
@@ -0,0 +1,28 @@
+package course
+
+import "testing"
+
+func TestSubjectAndLevel(t *testing.T) {
+	tests := []struct {
+		code    string
+		subject string
+		level   int
+	}{
+		{"CSCI-1200", "CSCI", 1000},
+		{"CSCI-4100", "CSCI", 4000},
+		{"MATH-2010", "MATH", 2000},
+		{"ECSE-6960", "ECSE", 6000},
+		{"ARCH-0999", "ARCH", 0},
+		{"PSYC-4960A", "PSYC", 4000},
+		{"CSCI 1200", "", 0},
+		{"csci-1200", "", 0},
+		{"", "", 0},
+	}
+
+	for _, test := range tests {
+		subject, level := SubjectAndLevel(test.code)
+		if subject != test.subject || level != test.level {
+			t.Errorf("%q: expected %v %v, got %v %v", test.code, test.subject, test.level, subject, level)
+		}
+	}
+}
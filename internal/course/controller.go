@@ -2,9 +2,13 @@ package course
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -43,37 +47,181 @@ func (cc *CourseController) FindCourseByID(w http.ResponseWriter, r *http.Reques
 }
 
 func (cc *CourseController) SearchCourse(w http.ResponseWriter, r *http.Request) {
-	// extract query params
+	// extract query params, a query is required unless filtering by subject or attribute
 	query := r.URL.Query().Get("query")
-	if query == "" {
+	subject := strings.ToUpper(r.URL.Query().Get("subject"))
+	attribute := r.URL.Query().Get("attribute")
+	if query == "" && subject == "" && attribute == "" {
 		http.Error(w, "missing query param", http.StatusBadRequest)
 		return
 	}
-	maxLimit := 10
-	limit := 5
+	maxLimit := 50
+	limit := 10
 	if limitQuery := r.URL.Query().Get("limit"); limitQuery != "" {
 		tmp, err := strconv.Atoi(limitQuery)
 		if err != nil || tmp <= 0 || tmp > maxLimit {
-			http.Error(w, fmt.Sprintf("invalid limit param: limit must be greater than 0 and then than %v", maxLimit), http.StatusBadRequest)
+			http.Error(w, fmt.Sprintf("invalid limit param: limit must be greater than 0 and less than %v", maxLimit), http.StatusBadRequest)
 			return
 		}
 		limit = tmp
 	}
+	offset := 0
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		tmp, err := DecodeSearchCursor(cursor)
+		if err != nil {
+			http.Error(w, "invalid cursor param", http.StatusBadRequest)
+			return
+		}
+		offset = tmp
+	} else if pageQuery := r.URL.Query().Get("page"); pageQuery != "" {
+		page, err := strconv.Atoi(pageQuery)
+		if err != nil || page <= 0 {
+			http.Error(w, "invalid page param: page must be greater than 0", http.StatusBadRequest)
+			return
+		}
+		offset = (page - 1) * limit
+	}
+	level := 0
+	if levelQuery := r.URL.Query().Get("level"); levelQuery != "" {
+		tmp, err := strconv.Atoi(levelQuery)
+		if err != nil || tmp < 1000 || tmp > 9000 || tmp%1000 != 0 {
+			http.Error(w, "invalid level param: level must be one of 1000, 2000, ..., 9000", http.StatusBadRequest)
+			return
+		}
+		level = tmp
+	}
+	term := r.URL.Query().Get("term")
+	if term != "" && !IsValidTerm(term) {
+		http.Error(w, "invalid term param: term must be one of fall, spring or summer", http.StatusBadRequest)
+		return
+	}
+	catalogYear := r.URL.Query().Get("catalogYear")
+	if catalogYear != "" && !IsValidCatalogYear(catalogYear) {
+		http.Error(w, "invalid catalogYear param: catalog year must be formatted as 2024-2025", http.StatusBadRequest)
+		return
+	}
+
+	search := CourseSearch{
+		Query:          query,
+		Limit:          limit,
+		Offset:         offset,
+		CatalogYear:    catalogYear,
+		Term:           term,
+		Subject:        subject,
+		Level:          level,
+		Attribute:      attribute,
+		IncludeRetired: r.URL.Query().Get("includeRetired") == "true",
+	}
 
-	// seach course
-	courses, err := cc.courseService.SearchCourse(query, limit)
+	// seach course, fuzzy search tolerates typos and scores every result
+	var courses interface{}
+	var total int
+	var nextCursor string
+	var err error
+	if r.URL.Query().Get("fuzzy") == "true" && query != "" {
+		var result *FuzzySearchResult
+		result, err = cc.courseService.FuzzySearchCourse(search)
+		if err == nil {
+			courses, total, nextCursor = result.Courses, result.Total, result.NextCursor
+		}
+	} else {
+		var result *CourseSearchResult
+		result, err = cc.courseService.SearchCourse(search)
+		if err == nil {
+			courses, total, nextCursor = result.Courses, result.Total, result.NextCursor
+		}
+	}
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			http.Error(w, "course not found", http.StatusNotFound)
+		if errors.Is(err, ErrInvalidSearchQuery) || errors.Is(err, ErrUnknownCatalogYear) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 		fmt.Println(err)
-		http.Error(w, "database fetch error: fetch course", http.StatusInternalServerError)
+		http.Error(w, "database fetch error: search course", http.StatusInternalServerError)
 		return
 	}
 
-	// Respond with json
+	// Respond with json, the body stays a bare array of courses and the paging
+	// is sent in headers
+	setSearchPageHeaders(w.Header(), r.URL, total, nextCursor)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(courses)
 }
+
+// setSearchPageHeaders sets the total number of results and, unless it is the
+// last page, a link to the next page i.e <...?query=csci&cursor=...>; rel="next"
+func setSearchPageHeaders(header http.Header, u *url.URL, total int, nextCursor string) {
+	header.Set("X-Total-Count", strconv.Itoa(total))
+	if nextCursor == "" {
+		return
+	}
+	header.Set("X-Next-Cursor", nextCursor)
+
+	next := *u
+	query := next.Query()
+	query.Del("page")
+	query.Set("cursor", nextCursor)
+	next.RawQuery = query.Encode()
+	header.Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.String()))
+}
+
+func (cc *CourseController) FindCatalogYears(w http.ResponseWriter, r *http.Request) {
+	// fetch catalog years from db
+	years, err := cc.courseService.CatalogYears()
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "database fetch error: fetch catalog years", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(years)
+}
+
+func (cc *CourseController) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	// extract query params
+	prefix := r.URL.Query().Get("prefix")
+	if prefix == "" {
+		http.Error(w, "missing prefix param", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(prefix) > MaxSearchQueryLength {
+		http.Error(w, fmt.Sprintf("invalid prefix param: prefix must be at most %v characters", MaxSearchQueryLength), http.StatusBadRequest)
+		return
+	}
+	maxLimit := 20
+	limit := 10
+	if limitQuery := r.URL.Query().Get("limit"); limitQuery != "" {
+		tmp, err := strconv.Atoi(limitQuery)
+		if err != nil || tmp <= 0 || tmp > maxLimit {
+			http.Error(w, fmt.Sprintf("invalid limit param: limit must be greater than 0 and less than %v", maxLimit), http.StatusBadRequest)
+			return
+		}
+		limit = tmp
+	}
+	catalogYear := r.URL.Query().Get("catalogYear")
+	if catalogYear != "" && !IsValidCatalogYear(catalogYear) {
+		http.Error(w, "invalid catalogYear param: catalog year must be formatted as 2024-2025", http.StatusBadRequest)
+		return
+	}
+
+	// suggest courses
+	suggestions, err := cc.courseService.Autocomplete(catalogYear, prefix, limit)
+	if err != nil {
+		if errors.Is(err, ErrUnknownCatalogYear) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database fetch error: autocomplete course", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(suggestions)
+}
@@ -0,0 +1,137 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrUnknownCatalogYear = errors.New("unknown catalog year")
+
+// CourseIndex keeps the courses of each catalog year in memory for searches
+// that can't be answered by mongo indexes, i.e fuzzy search and autocomplete.
+// Every catalog year is loaded on refresh and rebuilt after a catalog sync, so
+// requests never query mongo
+type CourseIndex struct {
+	courseStorage *CourseStorage
+
+	mu     sync.RWMutex
+	years  map[string]*catalogSnapshot
+	latest string
+}
+
+// The courses of a catalog year and the indexes built over them
+type catalogSnapshot struct {
+	courses  []CourseDB
+	trigrams *trigramIndex
+	prefixes *prefixIndex
+}
+
+func NewCourseIndex(cs *CourseStorage) *CourseIndex {
+	return &CourseIndex{
+		courseStorage: cs,
+		years:         make(map[string]*catalogSnapshot),
+	}
+}
+
+// LatestCatalogYear returns the latest catalog year as of the last refresh
+func (ci *CourseIndex) LatestCatalogYear() string {
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+	return ci.latest
+}
+
+// Refresh loads every catalog year from mongo, years without courses anymore
+// are dropped
+func (ci *CourseIndex) Refresh() error {
+	catalogYears, err := ci.courseStorage.FindCatalogYears()
+	if err != nil {
+		return err
+	}
+	// The latest year is empty if only courses ingested before catalogs were
+	// versioned exist
+	latest := ""
+	if len(catalogYears) > 0 {
+		latest = catalogYears[len(catalogYears)-1]
+	} else {
+		catalogYears = []string{latest}
+	}
+
+	years := make(map[string]*catalogSnapshot, len(catalogYears))
+	for _, year := range catalogYears {
+		snapshot, err := ci.build(year)
+		if err != nil {
+			return err
+		}
+		years[year] = snapshot
+	}
+
+	ci.mu.Lock()
+	ci.years = years
+	ci.latest = latest
+	ci.mu.Unlock()
+	return nil
+}
+
+// Watch refreshes the index whenever version changes until ctx is done, i.e
+// when another replica or the data worker cli synced the catalog
+func (ci *CourseIndex) Watch(ctx context.Context, interval time.Duration, version func() (string, error)) {
+	last, err := version()
+	if err != nil {
+		fmt.Println("Error fetching catalog version:", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current, err := version()
+			if err != nil {
+				fmt.Println("Error fetching catalog version:", err)
+				continue
+			}
+			if current == last {
+				continue
+			}
+			err = ci.Refresh()
+			if err != nil {
+				fmt.Println("Error refreshing course index:", err)
+				continue
+			}
+			last = current
+		}
+	}
+}
+
+// snapshot returns the courses of the catalog year as of the last refresh,
+// or ErrUnknownCatalogYear if the year had no courses
+func (ci *CourseIndex) snapshot(catalogYear string) (*catalogSnapshot, error) {
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+	snapshot, ok := ci.years[catalogYear]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCatalogYear, catalogYear)
+	}
+	return snapshot, nil
+}
+
+func (ci *CourseIndex) build(catalogYear string) (*catalogSnapshot, error) {
+	courses, err := ci.courseStorage.FindAllCourses(catalogYear)
+	if err != nil {
+		return nil, err
+	}
+	return newCatalogSnapshot(courses), nil
+}
+
+func newCatalogSnapshot(courses []CourseDB) *catalogSnapshot {
+	return &catalogSnapshot{
+		courses:  courses,
+		trigrams: newTrigramIndex(courses),
+		prefixes: newPrefixIndex(courses),
+	}
+}
@@ -0,0 +1,75 @@
+package course
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	COURSE_EQUIVALENCE_COLLECTION = "course_equivalences"
+)
+
+// A group of cross-listed courses that satisfy each other's requirements
+type CourseEquivalenceDB struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CatalogYear string             `bson:"catalogYear,omitempty" json:"catalogYear,omitempty"`
+	Codes       []string           `bson:"codes" json:"codes"`
+}
+
+func (s *CourseStorage) FindCourseEquivalences(catalogYear string) ([]CourseEquivalenceDB, error) {
+	collection := s.db.Collection(COURSE_EQUIVALENCE_COLLECTION)
+
+	cursor, err := collection.Find(context.Background(), bson.M{"catalogYear": CatalogYearFilter(catalogYear)})
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	var equivalences []CourseEquivalenceDB
+	err = cursor.All(context.Background(), &equivalences)
+	if err != nil {
+		return nil, err
+	}
+
+	return equivalences, nil
+}
+
+// ReplaceCourseEquivalences replaces every stored equivalence group of the
+// catalog year with groups
+func (s *CourseStorage) ReplaceCourseEquivalences(catalogYear string, groups [][]string) error {
+	collection := s.db.Collection(COURSE_EQUIVALENCE_COLLECTION)
+
+	models := []mongo.WriteModel{mongo.NewDeleteManyModel().SetFilter(bson.M{"catalogYear": CatalogYearFilter(catalogYear)})}
+	for _, codes := range groups {
+		models = append(models, mongo.NewInsertOneModel().SetDocument(CourseEquivalenceDB{CatalogYear: catalogYear, Codes: codes}))
+	}
+
+	_, err := collection.BulkWrite(context.Background(), models)
+	return err
+}
+
+// Equivalences maps a course code to every code it is equivalent to
+type Equivalences map[string][]string
+
+func NewEquivalences(groups []CourseEquivalenceDB) Equivalences {
+	e := make(Equivalences)
+	for _, group := range groups {
+		for _, code := range group.Codes {
+			e[code] = append(e[code], group.Codes...)
+		}
+	}
+	return e
+}
+
+// Mark marks the course and every equivalent course as taken
+func (e Equivalences) Mark(taken map[string]bool, code string) {
+	taken[code] = true
+	for _, equivalent := range e[code] {
+		taken[equivalent] = true
+	}
+}
@@ -0,0 +1,231 @@
+package course
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Lowest similarity of a fuzzy match, i.e "algorithims" matches "Algorithms"
+// with 0.7
+const minFuzzyScore = 0.3
+
+// A course with its fuzzy match score between 0 and 1
+type ScoredCourse struct {
+	CourseDB
+	Score float64 `json:"score"`
+}
+
+// One page of fuzzy search results, best matches first
+type FuzzySearchResult struct {
+	Courses    []ScoredCourse `json:"courses"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// FuzzySearch ranks the courses of the catalog year by trigram similarity of
+// their code and name to the query, so typos and missing spaces still match,
+// i.e "datastructures" or "algorithims"
+func (ci *CourseIndex) FuzzySearch(search CourseSearch) (*FuzzySearchResult, error) {
+	// Same validation as the regular search
+	_, err := ParseSearchQuery(search.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := ci.snapshot(search.CatalogYear)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []ScoredCourse{}
+	for i, score := range snapshot.trigrams.search(search.Query) {
+		c := &snapshot.courses[i]
+		if matchesSearchFilters(c, search) {
+			matches = append(matches, ScoredCourse{CourseDB: *c, Score: score})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Code < matches[j].Code
+	})
+
+	// Paginate
+	res := &FuzzySearchResult{
+		Courses: []ScoredCourse{},
+		Page:    search.Offset/search.Limit + 1,
+		Limit:   search.Limit,
+		Total:   len(matches),
+	}
+	if search.Offset < len(matches) {
+		end := search.Offset + search.Limit
+		if end > len(matches) {
+			end = len(matches)
+		}
+		res.Courses = matches[search.Offset:end]
+		if end < len(matches) {
+			res.NextCursor = EncodeSearchCursor(end)
+		}
+	}
+	return res, nil
+}
+
+// matchesSearchFilters is the in memory version of searchFilters
+func matchesSearchFilters(c *CourseDB, search CourseSearch) bool {
+	if c.Retired && !search.IncludeRetired {
+		return false
+	}
+	if !c.OfferedIn(search.Term) {
+		return false
+	}
+	if search.Subject != "" && c.Subject != search.Subject {
+		return false
+	}
+	if search.Level > 0 && c.Level != search.Level {
+		return false
+	}
+	if search.Attribute != "" {
+		for _, attribute := range c.Attributes {
+			if attribute == search.Attribute {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+type trigramSet map[string]struct{}
+
+// Trigram index over the code and name of every course. Each course has
+// several targets a query is compared to: the code, the whole name without
+// spaces, every name word and every pair of adjacent name words
+type trigramIndex struct {
+	targets  [][]trigramSet
+	postings map[string][]int
+}
+
+func newTrigramIndex(courses []CourseDB) *trigramIndex {
+	index := &trigramIndex{
+		targets:  make([][]trigramSet, len(courses)),
+		postings: make(map[string][]int),
+	}
+	for i := range courses {
+		words := fuzzyWords(courses[i].Name)
+		targets := []string{strings.Join(fuzzyWords(courses[i].Code), ""), strings.Join(words, "")}
+		for j, word := range words {
+			targets = append(targets, word)
+			if j+1 < len(words) {
+				targets = append(targets, word+words[j+1])
+			}
+		}
+
+		seen := make(trigramSet)
+		for _, target := range targets {
+			set := trigrams(target)
+			index.targets[i] = append(index.targets[i], set)
+			for trigram := range set {
+				if _, ok := seen[trigram]; !ok {
+					seen[trigram] = struct{}{}
+					index.postings[trigram] = append(index.postings[trigram], i)
+				}
+			}
+		}
+	}
+	return index
+}
+
+// search returns the score of every course matching the query by index
+func (index *trigramIndex) search(query string) map[int]float64 {
+	words := fuzzyWords(query)
+	if len(words) == 0 {
+		return map[int]float64{}
+	}
+	whole := trigrams(strings.Join(words, ""))
+	wordSets := make([]trigramSet, len(words))
+	for i, word := range words {
+		wordSets[i] = trigrams(word)
+	}
+
+	// Candidates share at least one trigram with the query
+	candidates := make(map[int]struct{})
+	for _, set := range append(wordSets, whole) {
+		for trigram := range set {
+			for _, i := range index.postings[trigram] {
+				candidates[i] = struct{}{}
+			}
+		}
+	}
+
+	scores := make(map[int]float64)
+	for i := range candidates {
+		targets := index.targets[i]
+
+		// The query as a single word, i.e "datastructures"
+		score := 0.0
+		for _, target := range targets {
+			score = maxScore(score, dice(whole, target))
+		}
+
+		// Every query word matched to its best target, i.e "intro algorithims"
+		if len(words) > 1 {
+			total := 0.0
+			for _, set := range wordSets {
+				best := 0.0
+				for _, target := range targets {
+					best = maxScore(best, dice(set, target))
+				}
+				total += best
+			}
+			score = maxScore(score, total/float64(len(words)))
+		}
+
+		if score >= minFuzzyScore {
+			scores[i] = score
+		}
+	}
+	return scores
+}
+
+// fuzzyWords returns the lowercase words of s, i.e "CSCI-1200" -> [csci 1200]
+func fuzzyWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams returns the trigrams of the word padded like pg_trgm so short words
+// and word starts weigh more, i.e "cs" -> ["  c", " cs", "cs "]
+func trigrams(word string) trigramSet {
+	runes := []rune("  " + word + " ")
+	set := make(trigramSet, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = struct{}{}
+	}
+	return set
+}
+
+// dice returns the Sørensen–Dice similarity of the trigram sets
+func dice(a trigramSet, b trigramSet) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range a {
+		if _, ok := b[trigram]; ok {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+func maxScore(a float64, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
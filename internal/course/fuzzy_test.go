@@ -0,0 +1,88 @@
+package course
+
+import (
+	"errors"
+	"testing"
+)
+
+func testCourseIndex() *CourseIndex {
+	courses := []CourseDB{
+		{Code: "CSCI-1100", Name: "Computer Science I", Subject: "CSCI", Level: 1000},
+		{Code: "CSCI-1200", Name: "Data Structures", Subject: "CSCI", Level: 1000, Offered: []string{TermFall, TermSpring}},
+		{Code: "CSCI-2300", Name: "Introduction to Algorithms", Subject: "CSCI", Level: 2000, Attributes: []string{"Data Intensive I"}},
+		{Code: "CSCI-4020", Name: "Design and Analysis of Algorithms", Subject: "CSCI", Level: 4000},
+		{Code: "MATH-1010", Name: "Calculus I", Subject: "MATH", Level: 1000},
+		{Code: "CSCI-1190", Name: "Beginning Programming", Subject: "CSCI", Level: 1000, Retired: true},
+	}
+	return &CourseIndex{
+		years:  map[string]*catalogSnapshot{"2024-2025": newCatalogSnapshot(courses)},
+		latest: "2024-2025",
+	}
+}
+
+func TestCourseIndex__FuzzySearch(t *testing.T) {
+	index := testCourseIndex()
+
+	tests := []struct {
+		query string
+		first string
+	}{
+		{"datastructures", "CSCI-1200"},
+		{"data structres", "CSCI-1200"},
+		{"algorithims", "CSCI-2300"},
+		{"intro algorithims", "CSCI-2300"},
+		{"csci1200", "CSCI-1200"},
+		{"calculus", "MATH-1010"},
+	}
+
+	for _, test := range tests {
+		res, err := index.FuzzySearch(CourseSearch{Query: test.query, CatalogYear: "2024-2025", Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Courses) == 0 || res.Courses[0].Code != test.first {
+			t.Errorf("%q: expected %s first, got %+v", test.query, test.first, res.Courses)
+			continue
+		}
+		if res.Courses[0].Score < minFuzzyScore || res.Courses[0].Score > 1 {
+			t.Errorf("%q: unexpected score %v", test.query, res.Courses[0].Score)
+		}
+	}
+}
+
+func TestCourseIndex__FuzzySearchFilters(t *testing.T) {
+	index := testCourseIndex()
+
+	res, err := index.FuzzySearch(CourseSearch{Query: "algorithms", CatalogYear: "2024-2025", Level: 4000, Limit: 10})
+	if err != nil || res.Total != 1 || res.Courses[0].Code != "CSCI-4020" {
+		t.Errorf("Expected only CSCI-4020, got %+v and %v", res, err)
+	}
+
+	res, err = index.FuzzySearch(CourseSearch{Query: "programming", CatalogYear: "2024-2025", Limit: 10})
+	if err != nil || res.Total != 0 {
+		t.Errorf("Expected retired courses to be excluded, got %+v and %v", res, err)
+	}
+
+	// Paginated by score
+	res, err = index.FuzzySearch(CourseSearch{Query: "algorithms", CatalogYear: "2024-2025", Limit: 1})
+	if err != nil || res.Total != 2 || len(res.Courses) != 1 || res.NextCursor != EncodeSearchCursor(1) {
+		t.Errorf("Expected the first of 2 matches with a next cursor, got %+v and %v", res, err)
+	}
+
+	if _, err := index.FuzzySearch(CourseSearch{Query: "C*S", CatalogYear: "2024-2025", Limit: 1}); err == nil {
+		t.Error("Expected an invalid query error")
+	}
+}
+
+func TestCourseIndex__UnknownCatalogYear(t *testing.T) {
+	index := testCourseIndex()
+
+	// A well formed year without courses is rejected before mongo is queried
+	_, err := index.FuzzySearch(CourseSearch{Query: "algorithms", CatalogYear: "1999-2000", Limit: 10})
+	if !errors.Is(err, ErrUnknownCatalogYear) {
+		t.Fatalf("expected ErrUnknownCatalogYear, got %v", err)
+	}
+	if _, ok := index.years["1999-2000"]; ok {
+		t.Errorf("expected the unknown year to not be cached")
+	}
+}
@@ -0,0 +1,53 @@
+package course
+
+var gradePoints = map[string]float64{
+	"A":  4.0,
+	"A-": 3.67,
+	"B+": 3.33,
+	"B":  3.0,
+	"B-": 2.67,
+	"C+": 2.33,
+	"C":  2.0,
+	"C-": 1.67,
+	"D+": 1.33,
+	"D":  1.0,
+	"F":  0.0,
+}
+
+// IsValidGrade reports whether grade is a letter grade, i.e "B+"
+func IsValidGrade(grade string) bool {
+	_, ok := gradePoints[grade]
+	return ok
+}
+
+// MeetsMinGrade reports whether grade satisfies a minimum grade requirement.
+// A grade that isn't recorded yet is assumed to pass, an F never passes and
+// an empty minGrade only requires a passing grade
+func MeetsMinGrade(grade string, minGrade string) bool {
+	if grade == "" {
+		return true
+	}
+	points, ok := gradePoints[grade]
+	if !ok || grade == "F" {
+		return false
+	}
+	if minGrade == "" {
+		return true
+	}
+	minPoints, ok := gradePoints[minGrade]
+	if !ok {
+		return true
+	}
+	return points >= minPoints
+}
+
+// HigherGrade reports whether grade is higher than other, i.e "B" is higher
+// than "D". Unknown grades are lower than every letter grade
+func HigherGrade(grade string, other string) bool {
+	points, ok := gradePoints[grade]
+	if !ok {
+		return false
+	}
+	otherPoints, ok := gradePoints[other]
+	return !ok || points > otherPoints
+}
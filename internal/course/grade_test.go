@@ -0,0 +1,24 @@
+package course
+
+import "testing"
+
+func TestHigherGrade(t *testing.T) {
+	tests := []struct {
+		grade    string
+		other    string
+		expected bool
+	}{
+		{"B", "D", true},
+		{"D", "B", false},
+		{"B", "B", false},
+		{"F", "", true},
+		{"", "F", false},
+		{"A", "P", true},
+	}
+
+	for _, test := range tests {
+		if higher := HigherGrade(test.grade, test.other); higher != test.expected {
+			t.Errorf("%q over %q: expected %v, got %v", test.grade, test.other, test.expected, higher)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package course
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes of the courses collection, creating an
+// existing index is a no-op
+func (s *CourseStorage) EnsureIndexes(ctx context.Context) error {
+	collection := s.db.Collection(COURSE_COLLECTION)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// Course lookups and syncs of a catalog year
+		{
+			Keys:    bson.D{{Key: "catalogYear", Value: 1}, {Key: "code", Value: 1}},
+			Options: options.Index().SetName("catalogYear_code"),
+		},
+		// Subject and level filters, i.e eligible 4000 level CSCI courses
+		{
+			Keys:    bson.D{{Key: "catalogYear", Value: 1}, {Key: "subject", Value: 1}, {Key: "level", Value: 1}},
+			Options: options.Index().SetName("catalogYear_subject_level"),
+		},
+		// Attribute filters, i.e Communication Intensive courses
+		{
+			Keys:    bson.D{{Key: "catalogYear", Value: 1}, {Key: "attributes", Value: 1}},
+			Options: options.Index().SetName("catalogYear_attributes"),
+		},
+		// Course search, a code match outranks a name match
+		{
+			Keys: bson.D{{Key: "code", Value: "text"}, {Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().SetName("course_text").SetWeights(bson.M{
+				"code":        10,
+				"name":        5,
+				"description": 1,
+			}),
+		},
+	})
+	return err
+}
+
+// BackfillSubjectAndLevel sets the subject and level of courses synced before
+// they were stored, so subject and level filters match them before the next
+// sync rewrites them. Returns the number of updated courses
+func (s *CourseStorage) BackfillSubjectAndLevel(ctx context.Context) (int, error) {
+	collection := s.db.Collection(COURSE_COLLECTION)
+
+	findOptions := options.Find().SetProjection(bson.M{"code": 1})
+	cursor, err := collection.Find(ctx, bson.M{"subject": bson.M{"$exists": false}}, findOptions)
+	if err != nil {
+		return 0, err
+	}
+
+	defer cursor.Close(ctx)
+
+	// Decode the results
+	var courses []CourseDB
+	err = cursor.All(ctx, &courses)
+	if err != nil {
+		return 0, err
+	}
+
+	models := []mongo.WriteModel{}
+	for _, c := range courses {
+		subject, level := SubjectAndLevel(c.Code)
+		if subject == "" {
+			continue
+		}
+		update := bson.M{"$set": bson.M{"subject": subject, "level": level}}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": c.ID}).SetUpdate(update))
+	}
+	if len(models) == 0 {
+		return 0, nil
+	}
+
+	result, err := collection.BulkWrite(ctx, models)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
@@ -0,0 +1,203 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	COURSE_OVERRIDE_COLLECTION = "course_overrides"
+)
+
+var (
+	ErrCourseOverrideNotFound = errors.New("course override not found")
+	ErrInvalidCourseOverride  = errors.New("invalid course override")
+)
+
+// Admin maintained corrections that are merged over the catalog source data
+// every time the course data worker syncs. Unset fields keep the source data,
+// an override without a catalog year applies to every catalog year
+type CourseOverrideDB struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Code        string             `bson:"code" json:"code"`
+	CatalogYear string             `bson:"catalogYear,omitempty" json:"catalogYear,omitempty"`
+	Name        string             `bson:"name,omitempty" json:"name,omitempty"`
+	Credits     *int               `bson:"credits,omitempty" json:"credits,omitempty"`
+	// Prerequisite expression parsed by ParsePrerequisiteExpr, i.e
+	// "CSCI-1200 and (CSCI-2200 or MATH-2800)". Empty removes all prerequisites
+	Prerequisites *string             `bson:"prerequisites,omitempty" json:"prerequisites,omitempty"`
+	Offered       []string            `bson:"offered,omitempty" json:"offered,omitempty"`
+	Restrictions  *CourseRestrictions `bson:"restrictions,omitempty" json:"restrictions,omitempty"`
+	// Why the override exists, i.e a link to the catalog erratum
+	Note      string    `bson:"note,omitempty" json:"note,omitempty"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Validate checks the override can be applied during a sync
+func (o *CourseOverrideDB) Validate() error {
+	if _, _, ok := ParseCode(o.Code); !ok {
+		return fmt.Errorf("%w: invalid course code %q", ErrInvalidCourseOverride, o.Code)
+	}
+	if o.CatalogYear != "" && !IsValidCatalogYear(o.CatalogYear) {
+		return fmt.Errorf("%w: %v", ErrInvalidCourseOverride, ErrInvalidCatalogYear)
+	}
+	if o.Credits != nil && *o.Credits < 0 {
+		return fmt.Errorf("%w: credits must not be negative", ErrInvalidCourseOverride)
+	}
+	if o.Prerequisites != nil {
+		if _, err := ParsePrerequisiteExpr(*o.Prerequisites); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCourseOverride, err)
+		}
+	}
+	for _, term := range o.Offered {
+		if !IsValidTerm(term) {
+			return fmt.Errorf("%w: invalid offered term %q", ErrInvalidCourseOverride, term)
+		}
+	}
+	if o.Restrictions != nil && o.Restrictions.MinClassStanding != "" {
+		if !IsValidClassStanding(o.Restrictions.MinClassStanding) {
+			return fmt.Errorf("%w: invalid class standing %q", ErrInvalidCourseOverride, o.Restrictions.MinClassStanding)
+		}
+	}
+	return nil
+}
+
+// AppliesTo reports whether the override applies to the catalog year
+func (o *CourseOverrideDB) AppliesTo(catalogYear string) bool {
+	return o.CatalogYear == "" || o.CatalogYear == catalogYear
+}
+
+// Apply merges the override over the course, only fields set on the override
+// are replaced. Returns the replaced fields
+func (o *CourseOverrideDB) Apply(c *CourseDB) []string {
+	fields := []string{}
+	if o.Name != "" {
+		c.Name = o.Name
+		fields = append(fields, "name")
+	}
+	if o.Credits != nil {
+		c.Credits = *o.Credits
+		fields = append(fields, "credits")
+	}
+	if o.Prerequisites != nil {
+		// Validated when the override was stored
+		prereqs, _ := ParsePrerequisiteExpr(*o.Prerequisites)
+		c.PrerequisiteTree = prereqs
+		c.Prerequisites = prereqs.Flatten()
+		fields = append(fields, "prerequisites")
+	}
+	if o.Offered != nil {
+		c.Offered = o.Offered
+		fields = append(fields, "offered")
+	}
+	if o.Restrictions != nil {
+		c.Restrictions = o.Restrictions
+		fields = append(fields, "restrictions")
+	}
+	return fields
+}
+
+// FindCourseOverrides returns the overrides sorted by course code, overrides of
+// every catalog year come before the overrides of a single catalog year so the
+// latter win when applied in order
+func (s *CourseStorage) FindCourseOverrides() ([]CourseOverrideDB, error) {
+	collection := s.db.Collection(COURSE_OVERRIDE_COLLECTION)
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "code", Value: 1}, {Key: "catalogYear", Value: 1}})
+	cursor, err := collection.Find(context.Background(), bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	overrides := []CourseOverrideDB{}
+	err = cursor.All(context.Background(), &overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
+func (s *CourseStorage) FindCourseOverrideByID(id string) (*CourseOverrideDB, error) {
+	collection := s.db.Collection(COURSE_OVERRIDE_COLLECTION)
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrCourseOverrideNotFound
+	}
+
+	// Find the override
+	var override CourseOverrideDB
+	err = collection.FindOne(context.Background(), bson.M{"_id": objId}).Decode(&override)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrCourseOverrideNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &override, nil
+}
+
+func (s *CourseStorage) CreateCourseOverride(override *CourseOverrideDB) (string, error) {
+	collection := s.db.Collection(COURSE_OVERRIDE_COLLECTION)
+
+	override.ID = primitive.NilObjectID
+	insertResult, err := collection.InsertOne(context.Background(), override)
+	if err != nil {
+		return "", err
+	}
+
+	return insertResult.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// ReplaceCourseOverride replaces every field of the override, fields missing
+// from the new override are unset
+func (s *CourseStorage) ReplaceCourseOverride(id string, override *CourseOverrideDB) error {
+	collection := s.db.Collection(COURSE_OVERRIDE_COLLECTION)
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrCourseOverrideNotFound
+	}
+
+	override.ID = objId
+	result, err := collection.ReplaceOne(context.Background(), bson.M{"_id": objId}, override)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrCourseOverrideNotFound
+	}
+
+	return nil
+}
+
+func (s *CourseStorage) DeleteCourseOverride(id string) error {
+	collection := s.db.Collection(COURSE_OVERRIDE_COLLECTION)
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrCourseOverrideNotFound
+	}
+
+	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": objId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrCourseOverrideNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package course
+
+import "strings"
+
+const (
+	PrerequisiteAnd    = "and"
+	PrerequisiteOr     = "or"
+	PrerequisiteCourse = "course"
+)
+
+// The full boolean prerequisite expression of a course, i.e
+// and(CSCI-1200, or(CSCI-2200, MATH-2800))
+type PrerequisiteExpr struct {
+	Type   string             `bson:"type" json:"type"`
+	Course string             `bson:"course,omitempty" json:"course,omitempty"`
+	Nested []PrerequisiteExpr `bson:"nested,omitempty" json:"nested,omitempty"`
+
+	// Course qualifiers, i.e "C or better in MATH-1010" or "may be taken concurrently"
+	MinGrade   string `bson:"minGrade,omitempty" json:"minGrade,omitempty"`
+	Concurrent bool   `bson:"concurrent,omitempty" json:"concurrent,omitempty"`
+}
+
+// Evaluate decides whether the expression is satisfied, leaf reports whether a
+// single course requirement is satisfied. An and/or without nested
+// expressions places no requirement and is always satisfied
+func (p *PrerequisiteExpr) Evaluate(leaf func(p *PrerequisiteExpr) bool) bool {
+	switch p.Type {
+	case PrerequisiteAnd:
+		for i := range p.Nested {
+			if !p.Nested[i].Evaluate(leaf) {
+				return false
+			}
+		}
+		return true
+	case PrerequisiteOr:
+		if len(p.Nested) == 0 {
+			return true
+		}
+		for i := range p.Nested {
+			if p.Nested[i].Evaluate(leaf) {
+				return true
+			}
+		}
+		return false
+	default:
+		return leaf(p)
+	}
+}
+
+// SatisfiedBy reports whether the expression is satisfied by the taken courses
+func (p *PrerequisiteExpr) SatisfiedBy(taken map[string]bool) bool {
+	return p.Evaluate(func(leaf *PrerequisiteExpr) bool {
+		return taken[leaf.Course]
+	})
+}
+
+// Courses returns the code of every course in the expression
+func (p *PrerequisiteExpr) Courses() []string {
+	if p.Type != PrerequisiteAnd && p.Type != PrerequisiteOr {
+		return []string{p.Course}
+	}
+	res := []string{}
+	for i := range p.Nested {
+		res = append(res, p.Nested[i].Courses()...)
+	}
+	return res
+}
+
+// Flatten converts the expression to the legacy and of ors format, i.e
+// "A and (B or C)" -> [[A] [B C]]
+func (p *PrerequisiteExpr) Flatten() [][]string {
+	if p == nil {
+		return [][]string{}
+	}
+	if p.Type != PrerequisiteAnd {
+		return [][]string{p.Courses()}
+	}
+	res := make([][]string, 0, len(p.Nested))
+	for i := range p.Nested {
+		res = append(res, p.Nested[i].Courses())
+	}
+	return res
+}
+
+func (p *PrerequisiteExpr) Equal(other *PrerequisiteExpr) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	if p.Type != other.Type || p.Course != other.Course || len(p.Nested) != len(other.Nested) ||
+		p.MinGrade != other.MinGrade || p.Concurrent != other.Concurrent {
+		return false
+	}
+	for i := range p.Nested {
+		if !p.Nested[i].Equal(&other.Nested[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// for print, i.e CSCI-1200 and (CSCI-2200 or MATH-2800 [min C])
+func (p *PrerequisiteExpr) String() string {
+	if p.Type != PrerequisiteAnd && p.Type != PrerequisiteOr {
+		res := p.Course
+		if p.MinGrade != "" {
+			res += " [min " + p.MinGrade + "]"
+		}
+		if p.Concurrent {
+			res += " [concurrent]"
+		}
+		return res
+	}
+	parts := make([]string, 0, len(p.Nested))
+	for i := range p.Nested {
+		part := p.Nested[i].String()
+		if len(p.Nested[i].Nested) > 1 {
+			part = "(" + part + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " "+p.Type+" ")
+}
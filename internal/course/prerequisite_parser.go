@@ -0,0 +1,152 @@
+package course
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidPrerequisiteExpr = errors.New("invalid prerequisite expression")
+
+// ParsePrerequisiteExpr parses a prerequisite expression in the format printed
+// by String, i.e "CSCI-1200 and (CSCI-2200 or MATH-2800 [min C])". And binds
+// tighter than or. Returns nil for an empty expression
+func ParsePrerequisiteExpr(s string) (*PrerequisiteExpr, error) {
+	tokens, err := tokenizePrerequisiteExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := prerequisiteParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidPrerequisiteExpr, p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenizePrerequisiteExpr splits the expression into parentheses, qualifiers
+// i.e "[min C]" and words
+func tokenizePrerequisiteExpr(s string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("%w: unclosed qualifier", ErrInvalidPrerequisiteExpr)
+			}
+			tokens = append(tokens, s[i:i+end+1])
+			i += end + 1
+		case unicode.IsSpace(rune(c)):
+			i++
+		default:
+			start := i
+			for i < len(s) && !unicode.IsSpace(rune(s[i])) && !strings.ContainsRune("()[]", rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+type prerequisiteParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *prerequisiteParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *prerequisiteParser) parseOr() (*PrerequisiteExpr, error) {
+	return p.parseList(PrerequisiteOr, p.parseAnd)
+}
+
+func (p *prerequisiteParser) parseAnd() (*PrerequisiteExpr, error) {
+	return p.parseList(PrerequisiteAnd, p.parseTerm)
+}
+
+// parseList parses operands joined by the operator, nested lists of the same
+// operator are merged i.e "A or (B or C)" -> or(A, B, C)
+func (p *prerequisiteParser) parseList(op string, operand func() (*PrerequisiteExpr, error)) (*PrerequisiteExpr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(p.peek(), op) {
+		return first, nil
+	}
+
+	list := &PrerequisiteExpr{Type: op}
+	list.add(first)
+	for strings.EqualFold(p.peek(), op) {
+		p.pos++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		list.add(next)
+	}
+	return list, nil
+}
+
+func (p *PrerequisiteExpr) add(nested *PrerequisiteExpr) {
+	if nested.Type == p.Type {
+		p.Nested = append(p.Nested, nested.Nested...)
+		return
+	}
+	p.Nested = append(p.Nested, *nested)
+}
+
+func (p *prerequisiteParser) parseTerm() (*PrerequisiteExpr, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("%w: unexpected end of expression", ErrInvalidPrerequisiteExpr)
+	case token == "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", ErrInvalidPrerequisiteExpr)
+		}
+		p.pos++
+		return expr, nil
+	case token == ")" || strings.HasPrefix(token, "[") ||
+		strings.EqualFold(token, PrerequisiteAnd) || strings.EqualFold(token, PrerequisiteOr):
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidPrerequisiteExpr, token)
+	}
+
+	p.pos++
+	expr := &PrerequisiteExpr{Type: PrerequisiteCourse, Course: token}
+	for strings.HasPrefix(p.peek(), "[") {
+		qualifier := strings.Fields(strings.Trim(p.peek(), "[]"))
+		switch {
+		case len(qualifier) == 2 && qualifier[0] == "min" && IsValidGrade(qualifier[1]):
+			expr.MinGrade = qualifier[1]
+		case len(qualifier) == 1 && qualifier[0] == "concurrent":
+			expr.Concurrent = true
+		default:
+			return nil, fmt.Errorf("%w: invalid qualifier %q", ErrInvalidPrerequisiteExpr, p.peek())
+		}
+		p.pos++
+	}
+	return expr, nil
+}
@@ -0,0 +1,146 @@
+package course
+
+import "testing"
+
+func leaf(code string) PrerequisiteExpr {
+	return PrerequisiteExpr{Type: PrerequisiteCourse, Course: code}
+}
+
+func TestPrerequisiteExpr__SatisfiedBy(t *testing.T) {
+	// CSCI-2300: CSCI-1200 and (CSCI-2200 or MATH-2800) and (MATH-1010 or MATH-1500)
+	algorithms := PrerequisiteExpr{
+		Type: PrerequisiteAnd,
+		Nested: []PrerequisiteExpr{
+			leaf("CSCI-1200"),
+			{Type: PrerequisiteOr, Nested: []PrerequisiteExpr{leaf("CSCI-2200"), leaf("MATH-2800")}},
+			{Type: PrerequisiteOr, Nested: []PrerequisiteExpr{leaf("MATH-1010"), leaf("MATH-1500")}},
+		},
+	}
+
+	// An or whose branches contain ands, lost by the [][]string flattening:
+	// (CSCI-1100 and MATH-1010) or CSCI-1200
+	orOfAnds := PrerequisiteExpr{
+		Type: PrerequisiteOr,
+		Nested: []PrerequisiteExpr{
+			{Type: PrerequisiteAnd, Nested: []PrerequisiteExpr{leaf("CSCI-1100"), leaf("MATH-1010")}},
+			leaf("CSCI-1200"),
+		},
+	}
+
+	// Deeply nested: (PHYS-1100 or (PHYS-1050 and MATH-1010)) and (CSCI-1100 or ENGR-1200)
+	deep := PrerequisiteExpr{
+		Type: PrerequisiteAnd,
+		Nested: []PrerequisiteExpr{
+			{Type: PrerequisiteOr, Nested: []PrerequisiteExpr{
+				leaf("PHYS-1100"),
+				{Type: PrerequisiteAnd, Nested: []PrerequisiteExpr{leaf("PHYS-1050"), leaf("MATH-1010")}},
+			}},
+			{Type: PrerequisiteOr, Nested: []PrerequisiteExpr{leaf("CSCI-1100"), leaf("ENGR-1200")}},
+		},
+	}
+
+	single := leaf("CSCI-1100")
+
+	tests := []struct {
+		name     string
+		expr     PrerequisiteExpr
+		taken    []string
+		expected bool
+	}{
+		{"single taken", single, []string{"CSCI-1100"}, true},
+		{"single missing", single, []string{"CSCI-1200"}, false},
+		{"and all groups", algorithms, []string{"CSCI-1200", "MATH-2800", "MATH-1010"}, true},
+		{"and missing group", algorithms, []string{"CSCI-1200", "CSCI-2200"}, false},
+		{"and nothing taken", algorithms, []string{}, false},
+		{"or of ands first branch", orOfAnds, []string{"CSCI-1100", "MATH-1010"}, true},
+		{"or of ands second branch", orOfAnds, []string{"CSCI-1200"}, true},
+		{"or of ands partial branch", orOfAnds, []string{"CSCI-1100"}, false},
+		{"deep nested and branch", deep, []string{"PHYS-1050", "MATH-1010", "ENGR-1200"}, true},
+		{"deep nested partial and branch", deep, []string{"PHYS-1050", "ENGR-1200"}, false},
+		{"deep nested leaf branch", deep, []string{"PHYS-1100", "CSCI-1100"}, true},
+		{"empty and", PrerequisiteExpr{Type: PrerequisiteAnd}, []string{}, true},
+		{"empty or", PrerequisiteExpr{Type: PrerequisiteOr}, []string{}, true},
+	}
+
+	for _, test := range tests {
+		taken := make(map[string]bool)
+		for _, code := range test.taken {
+			taken[code] = true
+		}
+		if actual := test.expr.SatisfiedBy(taken); actual != test.expected {
+			t.Errorf("%s: %s with %v expected %v, got %v", test.name, test.expr.String(), test.taken, test.expected, actual)
+		}
+	}
+}
+
+func TestPrerequisiteExpr__String(t *testing.T) {
+	expr := PrerequisiteExpr{
+		Type: PrerequisiteOr,
+		Nested: []PrerequisiteExpr{
+			{Type: PrerequisiteAnd, Nested: []PrerequisiteExpr{leaf("CSCI-1100"), leaf("MATH-1010")}},
+			leaf("CSCI-1200"),
+		},
+	}
+
+	expected := "(CSCI-1100 and MATH-1010) or CSCI-1200"
+	if expr.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, expr.String())
+	}
+}
+
+func TestMeetsMinGrade(t *testing.T) {
+	tests := []struct {
+		grade    string
+		minGrade string
+		expected bool
+	}{
+		{"", "C", true},
+		{"B", "", true},
+		{"F", "", false},
+		{"C", "C", true},
+		{"C-", "C", false},
+		{"A-", "B+", true},
+		{"D", "C-", false},
+	}
+
+	for _, test := range tests {
+		if actual := MeetsMinGrade(test.grade, test.minGrade); actual != test.expected {
+			t.Errorf("MeetsMinGrade(%q, %q) expected %v, got %v", test.grade, test.minGrade, test.expected, actual)
+		}
+	}
+}
+
+func TestParsePrerequisiteExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"CSCI-1100", "CSCI-1100"},
+		{"CSCI-1200 and (CSCI-2200 or MATH-2800)", "CSCI-1200 and (CSCI-2200 or MATH-2800)"},
+		{"CSCI-1100 and MATH-1010 or CSCI-1200", "(CSCI-1100 and MATH-1010) or CSCI-1200"},
+		{"CSCI-1100 OR (CSCI-1200 or CSCI-2200)", "CSCI-1100 or CSCI-1200 or CSCI-2200"},
+		{"((MATH-1010 [min C]))", "MATH-1010 [min C]"},
+		{"PHYS-1100 [concurrent] and MATH-1010 [min B+]", "PHYS-1100 [concurrent] and MATH-1010 [min B+]"},
+	}
+
+	for _, test := range tests {
+		expr, err := ParsePrerequisiteExpr(test.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.input, err)
+			continue
+		}
+		if expr.String() != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.input, test.expected, expr.String())
+		}
+	}
+
+	if expr, err := ParsePrerequisiteExpr("  "); expr != nil || err != nil {
+		t.Errorf("Expected no expression for blank input, got %v and %v", expr, err)
+	}
+
+	for _, input := range []string{"CSCI-1100 and", "(CSCI-1100", "CSCI-1100)", "or CSCI-1100", "CSCI-1100 [min Z]", "CSCI-1100 [min C"} {
+		if _, err := ParsePrerequisiteExpr(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package course
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// Longest query accepted by course search, in characters
+const MaxSearchQueryLength = 100
+
+// A parsed course search query. The query is never used as a pattern, user
+// input only reaches mongo as a literal code prefix or as text search words
+type SearchQuery struct {
+	// Code prefix to match, i.e CSCI-4 for "CSCI-4*" or "csci 4", or a subject
+	// prefix, i.e CS for "CS*"
+	CodePrefix string
+	// Words to match against the text index, i.e "data structures"
+	Terms string
+	// Words to match against the text index if no course code matches a query
+	// that only looks like a code, i.e "calc 1"
+	FallbackTerms string
+}
+
+// A query that looks like a course code or the start of one, i.e "CSCI-12",
+// "csci 1200" or "CSCI1200"
+var codeQueryPattern = regexp.MustCompile(`^([A-Za-z]{2,6})[- ]?(\d{1,4}[A-Za-z]?)$`)
+
+// An explicit prefix query without its trailing *, i.e "CS", "CSCI-" or "CSCI-4"
+var prefixQueryPattern = regexp.MustCompile(`^([A-Za-z]{2,6})([- ]?)(\d{0,4})$`)
+
+// ParseSearchQuery parses a course search query:
+//
+//	CSCI-4*          courses with codes starting with CSCI-4
+//	CSCI-*           courses of the subject
+//	CS*              courses of every subject starting with CS, i.e CSCI
+//	csci 1200        a code or the start of one, matched as a prefix or as
+//	                 words if no code matches
+//	data structures  words of the name, code or description
+//
+// A * is only allowed at the end of a subject or code prefix. Punctuation in
+// text queries is ignored so it can't change the meaning of the search
+func ParseSearchQuery(query string) (SearchQuery, error) {
+	query = strings.TrimSpace(query)
+	if !utf8.ValidString(query) {
+		return SearchQuery{}, fmt.Errorf("%w: query must be valid utf-8", ErrInvalidSearchQuery)
+	}
+	if utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return SearchQuery{}, fmt.Errorf("%w: query must be at most %v characters", ErrInvalidSearchQuery, MaxSearchQueryLength)
+	}
+
+	if strings.Contains(query, "*") {
+		match := prefixQueryPattern.FindStringSubmatch(strings.TrimSuffix(query, "*"))
+		if !strings.HasSuffix(query, "*") || match == nil {
+			return SearchQuery{}, fmt.Errorf("%w: * is only allowed at the end of a course code prefix, i.e CSCI-4*", ErrInvalidSearchQuery)
+		}
+		// Without a separator or number the subject itself is a prefix
+		if match[2] == "" && match[3] == "" {
+			return SearchQuery{CodePrefix: strings.ToUpper(match[1])}, nil
+		}
+		return SearchQuery{CodePrefix: strings.ToUpper(match[1] + "-" + match[3])}, nil
+	}
+
+	if match := codeQueryPattern.FindStringSubmatch(query); match != nil {
+		return SearchQuery{CodePrefix: strings.ToUpper(match[1] + "-" + match[2]), FallbackTerms: textSearchTerms(query)}, nil
+	}
+
+	return SearchQuery{Terms: textSearchTerms(query)}, nil
+}
+
+// textSearchTerms returns the words of the query for a mongo text search. Only
+// letters and digits are kept so the query can't use the $search negation or
+// phrase syntax, i.e "data-structures" -> "data structures"
+func textSearchTerms(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,209 @@
+package course
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected SearchQuery
+		invalid  bool
+	}{
+		{query: "CSCI-1200", expected: SearchQuery{CodePrefix: "CSCI-1200", FallbackTerms: "CSCI 1200"}},
+		{query: "csci 12", expected: SearchQuery{CodePrefix: "CSCI-12", FallbackTerms: "csci 12"}},
+		{query: " csci1200 ", expected: SearchQuery{CodePrefix: "CSCI-1200", FallbackTerms: "csci1200"}},
+		{query: "ITWS-4500X", expected: SearchQuery{CodePrefix: "ITWS-4500X", FallbackTerms: "ITWS 4500X"}},
+		{query: "calc 1", expected: SearchQuery{CodePrefix: "CALC-1", FallbackTerms: "calc 1"}},
+		{query: "CSCI-4*", expected: SearchQuery{CodePrefix: "CSCI-4"}},
+		{query: "csci*", expected: SearchQuery{CodePrefix: "CSCI"}},
+		{query: "CS*", expected: SearchQuery{CodePrefix: "CS"}},
+		{query: "CSCI-*", expected: SearchQuery{CodePrefix: "CSCI-"}},
+		{query: "CSCI *", expected: SearchQuery{CodePrefix: "CSCI-"}},
+		{query: "csci4*", expected: SearchQuery{CodePrefix: "CSCI-4"}},
+		{query: "CSCI", expected: SearchQuery{Terms: "CSCI"}},
+		{query: "data structures", expected: SearchQuery{Terms: "data structures"}},
+		{query: ".*", invalid: true},
+		{query: "*", invalid: true},
+		{query: "CSCI-4**", invalid: true},
+		{query: "CS*CI", invalid: true},
+		{query: "data*", expected: SearchQuery{CodePrefix: "DATA"}},
+		{query: "(a+)+$", expected: SearchQuery{Terms: "a"}},
+		{query: "[", expected: SearchQuery{}},
+		{query: strings.Repeat("a", MaxSearchQueryLength+1), invalid: true},
+		{query: "\xff", invalid: true},
+	}
+
+	for _, test := range tests {
+		query, err := ParseSearchQuery(test.query)
+		if test.invalid {
+			if !errors.Is(err, ErrInvalidSearchQuery) {
+				t.Errorf("%q: expected ErrInvalidSearchQuery, got %+v and %v", test.query, query, err)
+			}
+			continue
+		}
+		if err != nil || query != test.expected {
+			t.Errorf("%q: expected %+v, got %+v and %v", test.query, test.expected, query, err)
+		}
+	}
+}
+
+func TestTextSearchTerms(t *testing.T) {
+	tests := map[string]string{
+		"data structures":      "data structures",
+		"data-structures":      "data structures",
+		`-intro "algorithms"`:  "intro algorithms",
+		"  ":                   "",
+		"Élémentaire français": "Élémentaire français",
+	}
+
+	for query, expected := range tests {
+		if terms := textSearchTerms(query); terms != expected {
+			t.Errorf("%q: expected %q, got %q", query, expected, terms)
+		}
+	}
+}
+
+// A literal subject or code prefix, the only pattern course search sends to mongo
+var codePrefixPattern = regexp.MustCompile(`^[A-Z]{2,6}(-\d{0,4}[A-Z]?)?$`)
+
+func FuzzParseSearchQuery(f *testing.F) {
+	for _, seed := range []string{
+		"CSCI-1200", "csci 12", "CSCI-4*", "data structures", ".*", "(a+)+$", "[", `\`,
+		"-intro \"algorithms\"", "$where", "{\"$ne\": null}", "CSCI-4**", "\x00", "\xff",
+		strings.Repeat("(a*)*", 50),
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		start := time.Now()
+		query, err := ParseSearchQuery(s)
+		if time.Since(start) > 100*time.Millisecond {
+			t.Fatalf("%q: parsing took %v", s, time.Since(start))
+		}
+		if err != nil {
+			if !errors.Is(err, ErrInvalidSearchQuery) {
+				t.Fatalf("%q: unexpected error %v", s, err)
+			}
+			return
+		}
+		if query.CodePrefix != "" && !codePrefixPattern.MatchString(query.CodePrefix) {
+			t.Fatalf("%q: code prefix %q is not a literal code prefix", s, query.CodePrefix)
+		}
+		for _, r := range query.Terms {
+			if r != ' ' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				t.Fatalf("%q: terms %q contain %q", s, query.Terms, r)
+			}
+		}
+	})
+}
+
+func FuzzSearchQuery(f *testing.F) {
+	for _, seed := range []string{"CSCI-4*", "data structures", ".*", "(a+)+$", "", "!!"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		filter, _, ok, err := searchQuery(CourseSearch{Query: s, Limit: 10})
+		if err != nil {
+			if !errors.Is(err, ErrInvalidSearchQuery) {
+				t.Fatalf("%q: unexpected error %v", s, err)
+			}
+			return
+		}
+		if !ok {
+			return
+		}
+
+		// Every pattern sent to mongo is an anchored literal code prefix
+		for _, condition := range filter["$and"].(bson.A) {
+			code, isCode := condition.(bson.M)["code"]
+			if !isCode {
+				continue
+			}
+			pattern := code.(primitive.Regex).Pattern
+			prefix := strings.TrimPrefix(pattern, "^")
+			if !strings.HasPrefix(pattern, "^") || !codePrefixPattern.MatchString(prefix) {
+				t.Fatalf("%q: unexpected code pattern %q", s, pattern)
+			}
+		}
+	})
+}
+
+func TestFallbackSearchQuery(t *testing.T) {
+	tests := map[string]string{
+		"calc 1":          "calc 1",
+		"CSCI-1200":       "CSCI 1200",
+		"CSCI-4*":         "",
+		"data structures": "",
+		"":                "",
+	}
+
+	for query, expected := range tests {
+		filter, _, ok, err := fallbackSearchQuery(CourseSearch{Query: query, Limit: 10})
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", query, err)
+		}
+		if ok != (expected != "") {
+			t.Errorf("%q: expected fallback %v, got %v", query, expected != "", ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		terms := ""
+		for _, condition := range filter["$and"].(bson.A) {
+			if text, isText := condition.(bson.M)["$text"]; isText {
+				terms = text.(bson.M)["$search"].(string)
+			}
+		}
+		if terms != expected {
+			t.Errorf("%q: expected text search %q, got %q", query, expected, terms)
+		}
+	}
+}
+
+func TestSearchQuery__CodePrefix(t *testing.T) {
+	codes := []string{"CS-1000", "CSCI-1200", "CSCI-4430", "ITWS-1100"}
+	tests := map[string][]string{
+		"CS*":     {"CS-1000", "CSCI-1200", "CSCI-4430"},
+		"csci*":   {"CSCI-1200", "CSCI-4430"},
+		"CS-*":    {"CS-1000"},
+		"CSCI-4*": {"CSCI-4430"},
+	}
+
+	for query, expected := range tests {
+		filter, _, ok, err := searchQuery(CourseSearch{Query: query, Limit: 10})
+		if err != nil || !ok {
+			t.Fatalf("%q: expected a search, got %v and %v", query, ok, err)
+		}
+		var pattern *regexp.Regexp
+		for _, condition := range filter["$and"].(bson.A) {
+			if code, isCode := condition.(bson.M)["code"]; isCode {
+				pattern = regexp.MustCompile(code.(primitive.Regex).Pattern)
+			}
+		}
+		if pattern == nil {
+			t.Fatalf("%q: expected a code condition, got %v", query, filter)
+		}
+		matched := []string{}
+		for _, code := range codes {
+			if pattern.MatchString(code) {
+				matched = append(matched, code)
+			}
+		}
+		if !reflect.DeepEqual(matched, expected) {
+			t.Errorf("%q: expected %v to match, got %v", query, expected, matched)
+		}
+	}
+}
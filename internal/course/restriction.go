@@ -0,0 +1,88 @@
+package course
+
+import "strings"
+
+const (
+	StandingFreshman  = "freshman"
+	StandingSophomore = "sophomore"
+	StandingJunior    = "junior"
+	StandingSenior    = "senior"
+)
+
+var standings = []string{StandingFreshman, StandingSophomore, StandingJunior, StandingSenior}
+
+// Credits a student needs to reach each class standing
+var standingCredits = map[string]int{
+	StandingFreshman:  0,
+	StandingSophomore: 30,
+	StandingJunior:    60,
+	StandingSenior:    90,
+}
+
+// IsValidClassStanding reports whether standing is a class standing, i.e junior
+func IsValidClassStanding(standing string) bool {
+	_, ok := standingCredits[standing]
+	return ok
+}
+
+// Credits assumed for courses without credit data
+const DefaultCourseCredits = 4
+
+// Who may register for a course
+type CourseRestrictions struct {
+	// Lowest class standing allowed to register, i.e junior for juniors and up
+	MinClassStanding string `bson:"minClassStanding,omitempty" json:"minClassStanding,omitempty"`
+	// Subjects of the majors allowed to register, i.e CSCI
+	Majors             []string `bson:"majors,omitempty" json:"majors,omitempty"`
+	PermissionRequired bool     `bson:"permissionRequired,omitempty" json:"permissionRequired,omitempty"`
+}
+
+// ClassStanding returns the class standing reached with the accumulated credits
+func ClassStanding(credits int) string {
+	res := StandingFreshman
+	for _, standing := range standings {
+		if credits >= standingCredits[standing] {
+			res = standing
+		}
+	}
+	return res
+}
+
+// AllowsStanding reports whether a student of the class standing may register
+func (r *CourseRestrictions) AllowsStanding(standing string) bool {
+	if r.MinClassStanding == "" {
+		return true
+	}
+	return standingCredits[standing] >= standingCredits[r.MinClassStanding]
+}
+
+// AllowsMajor reports whether a student of the major may register
+func (r *CourseRestrictions) AllowsMajor(major string) bool {
+	if len(r.Majors) == 0 {
+		return true
+	}
+	for _, m := range r.Majors {
+		if strings.EqualFold(m, major) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *CourseRestrictions) Equal(other *CourseRestrictions) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.MinClassStanding != other.MinClassStanding || r.PermissionRequired != other.PermissionRequired {
+		return false
+	}
+	if len(r.Majors) != len(other.Majors) {
+		return false
+	}
+	for i := range r.Majors {
+		if r.Majors[i] != other.Majors[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -6,4 +6,6 @@ func AddCourseRoutes(r chi.Router, controller *CourseController) {
 
 	r.Get("/api/courses/{courseID}", controller.FindCourseByID)
 	r.Get("/api/courses/search/", controller.SearchCourse)
+	r.Get("/api/courses/autocomplete", controller.Autocomplete)
+	r.Get("/api/courses/catalog-years", controller.FindCatalogYears)
 }
@@ -0,0 +1,187 @@
+package course
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrInvalidSearchCursor = errors.New("invalid search cursor")
+
+// One page of search results, next cursor is empty on the last page
+type CourseSearchResult struct {
+	Courses    []CourseDB `json:"courses"`
+	Page       int        `json:"page"`
+	Limit      int        `json:"limit"`
+	Total      int        `json:"total"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// EncodeSearchCursor returns the cursor of the result at the offset
+func EncodeSearchCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+// DecodeSearchCursor returns the offset of the cursor
+func DecodeSearchCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidSearchCursor
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), "o:"))
+	if err != nil || !strings.HasPrefix(string(data), "o:") || offset < 0 {
+		return 0, ErrInvalidSearchCursor
+	}
+	return offset, nil
+}
+
+// SearchCourses finds one page of courses. Code queries match code prefixes
+// sorted by code so the exact code comes first, falling back to the text index
+// if no code matches a query that only looks like a code. Other queries use the text
+// index over name, code and description ranked by relevance. An empty query
+// returns every course matching the filters sorted by code
+func (s *CourseStorage) SearchCourses(search CourseSearch) (*CourseSearchResult, error) {
+	collection := s.db.Collection(COURSE_COLLECTION)
+
+	res := &CourseSearchResult{
+		Courses: []CourseDB{},
+		Page:    search.Offset/search.Limit + 1,
+		Limit:   search.Limit,
+	}
+
+	filter, findOptions, ok, err := searchQuery(search)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return res, nil
+	}
+
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// A query that only looks like a code, i.e "calc 1", searches the text
+	// index if no course code matches
+	if total == 0 {
+		fallbackFilter, fallbackOptions, ok, err := fallbackSearchQuery(search)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filter, findOptions = fallbackFilter, fallbackOptions
+			total, err = collection.CountDocuments(context.Background(), filter)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res.Total = int(total)
+	if search.Offset >= res.Total {
+		return res, nil
+	}
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	err = cursor.All(context.Background(), &res.Courses)
+	if err != nil {
+		return nil, err
+	}
+
+	if next := search.Offset + len(res.Courses); next < res.Total {
+		res.NextCursor = EncodeSearchCursor(next)
+	}
+	return res, nil
+}
+
+// searchQuery builds the filter and options of the search, returns false if
+// the query has nothing to search i.e only punctuation
+func searchQuery(search CourseSearch) (bson.M, *options.FindOptions, bool, error) {
+	query, err := ParseSearchQuery(search.Query)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	filter, findOptions, ok := buildSearchQuery(search, query)
+	return filter, findOptions, ok, nil
+}
+
+// fallbackSearchQuery builds the text search of a query that only looks like
+// a course code, returns false if the query has no fallback
+func fallbackSearchQuery(search CourseSearch) (bson.M, *options.FindOptions, bool, error) {
+	query, err := ParseSearchQuery(search.Query)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if query.FallbackTerms == "" {
+		return nil, nil, false, nil
+	}
+	filter, findOptions, ok := buildSearchQuery(search, SearchQuery{Terms: query.FallbackTerms})
+	return filter, findOptions, ok, nil
+}
+
+func buildSearchQuery(search CourseSearch, query SearchQuery) (bson.M, *options.FindOptions, bool) {
+	conditions := searchFilters(search)
+	findOptions := options.Find().SetSkip(int64(search.Offset)).SetLimit(int64(search.Limit))
+	switch {
+	case query.CodePrefix != "":
+		// Anchored case sensitive literal prefix, can use the code index
+		conditions = append(conditions, bson.M{"code": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.CodePrefix)}})
+		findOptions.SetSort(bson.D{{Key: "code", Value: 1}})
+	case query.Terms != "":
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": query.Terms}})
+		score := bson.M{"$meta": "textScore"}
+		findOptions.SetProjection(bson.M{"score": score})
+		findOptions.SetSort(bson.D{{Key: "score", Value: score}, {Key: "code", Value: 1}})
+	case strings.TrimSpace(search.Query) == "":
+		findOptions.SetSort(bson.D{{Key: "code", Value: 1}})
+	default:
+		return nil, nil, false
+	}
+
+	return bson.M{"$and": conditions}, findOptions, true
+}
+
+// searchFilters returns the conditions of the search filters
+func searchFilters(search CourseSearch) bson.A {
+	conditions := bson.A{
+		bson.M{"catalogYear": CatalogYearFilter(search.CatalogYear)},
+	}
+
+	// Only keep courses offered in the term, courses without offering data
+	// are assumed to be offered every term
+	if search.Term != "" {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"offered": search.Term},
+			bson.M{"offered": bson.M{"$exists": false}},
+			bson.M{"offered": bson.M{"$size": 0}},
+		}})
+	}
+	if search.Subject != "" {
+		conditions = append(conditions, bson.M{"subject": search.Subject})
+	}
+	if search.Level > 0 {
+		conditions = append(conditions, bson.M{"level": search.Level})
+	}
+	if search.Attribute != "" {
+		conditions = append(conditions, bson.M{"attributes": search.Attribute})
+	}
+	if !search.IncludeRetired {
+		conditions = append(conditions, bson.M{"retired": bson.M{"$ne": true}})
+	}
+	return conditions
+}
@@ -0,0 +1,45 @@
+package course
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestSearchCursor(t *testing.T) {
+	offset, err := DecodeSearchCursor(EncodeSearchCursor(40))
+	if err != nil || offset != 40 {
+		t.Errorf("Expected offset 40, got %v and %v", offset, err)
+	}
+
+	for _, cursor := range []string{"40", "!!", EncodeSearchCursor(-1)} {
+		if _, err := DecodeSearchCursor(cursor); err != ErrInvalidSearchCursor {
+			t.Errorf("%q: expected ErrInvalidSearchCursor, got %v", cursor, err)
+		}
+	}
+}
+
+func TestSetSearchPageHeaders(t *testing.T) {
+	u, _ := url.Parse("/api/courses/search/?query=csci&page=2&limit=10")
+	cursor := EncodeSearchCursor(20)
+
+	header := http.Header{}
+	setSearchPageHeaders(header, u, 42, cursor)
+	if total := header.Get("X-Total-Count"); total != "42" {
+		t.Errorf("Expected a total of 42, got %q", total)
+	}
+	if next := header.Get("X-Next-Cursor"); next != cursor {
+		t.Errorf("Expected the next cursor %q, got %q", cursor, next)
+	}
+	expected := "</api/courses/search/?cursor=" + cursor + "&limit=10&query=csci>; rel=\"next\""
+	if link := header.Get("Link"); link != expected {
+		t.Errorf("Expected the link %q, got %q", expected, link)
+	}
+
+	// The last page has no next page
+	header = http.Header{}
+	setSearchPageHeaders(header, u, 42, "")
+	if header.Get("X-Total-Count") != "42" || header.Get("Link") != "" || header.Get("X-Next-Cursor") != "" {
+		t.Errorf("Expected only the total on the last page, got %v", header)
+	}
+}
@@ -1,19 +1,104 @@
 package course
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
 type CourseService struct {
 	courseStorage *CourseStorage
+	courseIndex   *CourseIndex
 }
 
-func NewCourseService(cs *CourseStorage) *CourseService {
+func NewCourseService(cs *CourseStorage, ci *CourseIndex) *CourseService {
 	return &CourseService{
 		courseStorage: cs,
+		courseIndex:   ci,
 	}
 }
 
+type CourseSearch struct {
+	// Course code prefix or words of the name, code or description
+	Query string
+	// Page size and number of results to skip
+	Limit  int
+	Offset int
+	// Catalog year to search, defaults to the latest catalog year
+	CatalogYear string
+	// Only return courses offered in this term
+	Term string
+	// Only return courses of the subject, level and attribute, i.e CSCI, 4000
+	// and Communication Intensive
+	Subject   string
+	Level     int
+	Attribute string
+	// Also return courses retired from the catalog
+	IncludeRetired bool
+}
+
 func (cs *CourseService) FindCourseByID(id string) (*CourseDB, error) {
 	return cs.courseStorage.FindCourseByID(id)
 }
 
-func (cs *CourseService) SearchCourse(query string, limit int) ([]CourseDB, error) {
-	return cs.courseStorage.FindCourseByNameOrCode(query, limit)
+func (cs *CourseService) FindCoursesByIDs(ids []primitive.ObjectID) ([]CourseDB, error) {
+	return cs.courseStorage.FindCoursesByIDs(ids)
+}
+
+func (cs *CourseService) FindCoursesByCodes(catalogYear string, codes []string) ([]CourseDB, error) {
+	return cs.courseStorage.FindCoursesByCodes(catalogYear, codes)
+}
+
+func (cs *CourseService) FindCourses(catalogYear string, subject string, level int, attribute string) ([]CourseDB, error) {
+	return cs.courseStorage.FindCourses(catalogYear, subject, level, attribute)
+}
+
+func (cs *CourseService) CatalogYears() ([]string, error) {
+	return cs.courseStorage.FindCatalogYears()
+}
+
+// LatestCatalogYear returns the most recent catalog year, or the empty year if
+// only courses ingested before catalogs were versioned exist
+func (cs *CourseService) LatestCatalogYear() (string, error) {
+	years, err := cs.courseStorage.FindCatalogYears()
+	if err != nil {
+		return "", err
+	}
+	if len(years) == 0 {
+		return "", nil
+	}
+	return years[len(years)-1], nil
+}
+
+// Equivalences returns the cross-listing equivalences of the catalog year
+// persisted by the last course data worker run
+func (cs *CourseService) Equivalences(catalogYear string) (Equivalences, error) {
+	groups, err := cs.courseStorage.FindCourseEquivalences(catalogYear)
+	if err != nil {
+		return nil, err
+	}
+	return NewEquivalences(groups), nil
+}
+
+// SearchCourse searches the courses of the catalog year, defaulting to the
+// latest catalog year cached by the course index
+func (cs *CourseService) SearchCourse(search CourseSearch) (*CourseSearchResult, error) {
+	if search.CatalogYear == "" {
+		search.CatalogYear = cs.courseIndex.LatestCatalogYear()
+	}
+	return cs.courseStorage.SearchCourses(search)
+}
+
+// FuzzySearchCourse ranks courses by similarity to the query using the in
+// memory course index, defaulting to the latest catalog year it cached
+func (cs *CourseService) FuzzySearchCourse(search CourseSearch) (*FuzzySearchResult, error) {
+	if search.CatalogYear == "" {
+		search.CatalogYear = cs.courseIndex.LatestCatalogYear()
+	}
+	return cs.courseIndex.FuzzySearch(search)
+}
+
+// Autocomplete suggests courses for a code or name prefix from the in memory
+// course index, without querying mongo
+func (cs *CourseService) Autocomplete(catalogYear string, prefix string, limit int) ([]AutocompleteSuggestion, error) {
+	if catalogYear == "" {
+		catalogYear = cs.courseIndex.LatestCatalogYear()
+	}
+	return cs.courseIndex.Autocomplete(catalogYear, prefix, limit)
 }
@@ -3,6 +3,7 @@ package course
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,14 +15,27 @@ var (
 	COURSE_COLLECTION = "courses"
 )
 
-// How Course looks in MongoDB
+// How Course looks in MongoDB. Courses removed from the catalog are retired
+// instead of deleted since degree plans reference them
 type CourseDB struct {
-	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Name          string             `bson:"name" json:"name"`
-	Code          string             `bson:"code" json:"code"`
-	Prerequisites [][]string         `bson:"prerequisites" json:"prerequisites"`
-	Corequisites  []string           `bson:"corequisites" json:"corequisites"`
-	CrossListings []string           `bson:"crossListings" json:"crossListings"`
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string             `bson:"name" json:"name"`
+	Code        string             `bson:"code" json:"code"`
+	CatalogYear string             `bson:"catalogYear,omitempty" json:"catalogYear,omitempty"`
+	Subject     string             `bson:"subject,omitempty" json:"subject,omitempty"` // i.e CSCI
+	Level       int                `bson:"level,omitempty" json:"level,omitempty"`     // i.e 4000 for CSCI-4100
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	// Catalog attributes, i.e [Communication Intensive, HASS Inquiry]
+	Attributes       []string            `bson:"attributes,omitempty" json:"attributes,omitempty"`
+	Prerequisites    [][]string          `bson:"prerequisites" json:"prerequisites"`
+	PrerequisiteTree *PrerequisiteExpr   `bson:"prerequisiteTree,omitempty" json:"prerequisiteTree,omitempty"`
+	Corequisites     []string            `bson:"corequisites" json:"corequisites"`
+	CrossListings    []string            `bson:"crossListings" json:"crossListings"`
+	Offered          []string            `bson:"offered,omitempty" json:"offered,omitempty"`
+	Credits          int                 `bson:"credits,omitempty" json:"credits,omitempty"`
+	Restrictions     *CourseRestrictions `bson:"restrictions,omitempty" json:"restrictions,omitempty"`
+	Retired          bool                `bson:"retired,omitempty" json:"retired,omitempty"`
+	RetiredAt        *time.Time          `bson:"retiredAt,omitempty" json:"retiredAt,omitempty"`
 }
 
 type CourseStorage struct {
@@ -35,18 +49,93 @@ func NewCourseStorage(db *mongo.Database) *CourseStorage {
 	}
 }
 
-func (s *CourseStorage) FindCourseByNameOrCode(query string, limit int) ([]CourseDB, error) {
+func (s *CourseStorage) FindCourseByID(id string) (*CourseDB, error) {
+	collection := s.db.Collection(COURSE_COLLECTION)
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the course by ID
+	var course CourseDB
+	err = collection.FindOne(context.Background(), bson.M{"_id": objId}).Decode(&course)
+	if err != nil {
+		return nil, err
+	}
+
+	return &course, nil
+}
+
+func (s *CourseStorage) FindCoursesByIDs(ids []primitive.ObjectID) ([]CourseDB, error) {
+	collection := s.db.Collection(COURSE_COLLECTION)
+
+	cursor, err := collection.Find(context.Background(), bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	var courses []CourseDB
+	err = cursor.All(context.Background(), &courses)
+	if err != nil {
+		return nil, err
+	}
+
+	return courses, nil
+}
+
+// FindAllCourses returns every course of the catalog year, including retired courses
+func (s *CourseStorage) FindAllCourses(catalogYear string) ([]CourseDB, error) {
+	collection := s.db.Collection(COURSE_COLLECTION)
+
+	cursor, err := collection.Find(context.Background(), bson.M{"catalogYear": CatalogYearFilter(catalogYear)})
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	var courses []CourseDB
+	err = cursor.All(context.Background(), &courses)
+	if err != nil {
+		return nil, err
+	}
+
+	return courses, nil
+}
+
+// BulkWriteCourses applies the writes to the courses collection, i.e the
+// upserts and retirements of a catalog sync
+func (s *CourseStorage) BulkWriteCourses(ctx context.Context, models []mongo.WriteModel) (*mongo.BulkWriteResult, error) {
+	collection := s.db.Collection(COURSE_COLLECTION)
+	return collection.BulkWrite(ctx, models)
+}
+
+// FindCourses returns every current course of the catalog year matching the
+// subject, level and attribute, an empty subject, a zero level or an empty
+// attribute matches all courses
+func (s *CourseStorage) FindCourses(catalogYear string, subject string, level int, attribute string) ([]CourseDB, error) {
 	collection := s.db.Collection(COURSE_COLLECTION)
 
-	// Find the course by name or code
 	filter := bson.M{
-		"$or": bson.A{
-			bson.M{"name": primitive.Regex{Pattern: query, Options: "i"}},
-			bson.M{"code": primitive.Regex{Pattern: query, Options: "i"}},
-		},
+		"retired":     bson.M{"$ne": true},
+		"catalogYear": CatalogYearFilter(catalogYear),
+	}
+	if subject != "" {
+		filter["subject"] = subject
+	}
+	if level > 0 {
+		filter["level"] = level
+	}
+	if attribute != "" {
+		filter["attributes"] = attribute
 	}
 
-	findOptions := options.Find().SetLimit(int64(limit))
+	findOptions := options.Find().SetSort(bson.M{"code": 1})
 	cursor, err := collection.Find(context.Background(), filter, findOptions)
 	if err != nil {
 		return nil, err
@@ -64,22 +153,29 @@ func (s *CourseStorage) FindCourseByNameOrCode(query string, limit int) ([]Cours
 	return courses, nil
 }
 
-func (s *CourseStorage) FindCourseByID(id string) (*CourseDB, error) {
+// FindCoursesByCodes returns the courses of the catalog year with the codes
+func (s *CourseStorage) FindCoursesByCodes(catalogYear string, codes []string) ([]CourseDB, error) {
 	collection := s.db.Collection(COURSE_COLLECTION)
 
-	objId, err := primitive.ObjectIDFromHex(id)
+	filter := bson.M{
+		"code":        bson.M{"$in": codes},
+		"catalogYear": CatalogYearFilter(catalogYear),
+	}
+	cursor, err := collection.Find(context.Background(), filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Find the course by ID
-	var course CourseDB
-	err = collection.FindOne(context.Background(), bson.M{"_id": objId}).Decode(&course)
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	var courses []CourseDB
+	err = cursor.All(context.Background(), &courses)
 	if err != nil {
 		return nil, err
 	}
 
-	return &course, nil
+	return courses, nil
 }
 
 // Course functions
@@ -90,6 +186,17 @@ func (c *CourseDB) Equal(other *CourseDB) bool {
 	if c.Code != other.Code {
 		return false
 	}
+	if c.Subject != other.Subject || c.Level != other.Level || c.Description != other.Description {
+		return false
+	}
+	if len(c.Attributes) != len(other.Attributes) {
+		return false
+	}
+	for i := range c.Attributes {
+		if c.Attributes[i] != other.Attributes[i] {
+			return false
+		}
+	}
 	if len(c.Prerequisites) != len(other.Prerequisites) {
 		return false
 	}
@@ -103,6 +210,9 @@ func (c *CourseDB) Equal(other *CourseDB) bool {
 			}
 		}
 	}
+	if !c.PrerequisiteTree.Equal(other.PrerequisiteTree) {
+		return false
+	}
 	if len(c.Corequisites) != len(other.Corequisites) {
 		return false
 	}
@@ -119,9 +229,46 @@ func (c *CourseDB) Equal(other *CourseDB) bool {
 			return false
 		}
 	}
+	if len(c.Offered) != len(other.Offered) {
+		return false
+	}
+	for i := range c.Offered {
+		if c.Offered[i] != other.Offered[i] {
+			return false
+		}
+	}
+	if c.Credits != other.Credits {
+		return false
+	}
+	if !c.Restrictions.Equal(other.Restrictions) {
+		return false
+	}
 	return true
 }
 
+// CreditsOrDefault returns the credits of the course, or the default credits
+// if the catalog has no credit data
+func (c *CourseDB) CreditsOrDefault() int {
+	if c.Credits == 0 {
+		return DefaultCourseCredits
+	}
+	return c.Credits
+}
+
+// OfferedIn reports whether the course is offered in the given term. Courses
+// without offering data are assumed to be offered every term.
+func (c *CourseDB) OfferedIn(term string) bool {
+	if len(c.Offered) == 0 || term == "" {
+		return true
+	}
+	for _, t := range c.Offered {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
+
 // for print
 func (c *CourseDB) String() string {
 	return fmt.Sprintf("%s %s %v %v %v", c.Code, c.Name, c.Prerequisites, c.Corequisites, c.CrossListings)
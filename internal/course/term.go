@@ -0,0 +1,67 @@
+package course
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	TermFall   = "fall"
+	TermSpring = "spring"
+	TermSummer = "summer"
+)
+
+var terms = []string{TermFall, TermSpring, TermSummer}
+
+// IsValidTerm reports whether term is one of the known academic terms
+func IsValidTerm(term string) bool {
+	for _, t := range terms {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
+
+// Terms mentioned after a negation in a clause are excluded, i.e "not offered
+// in spring" or "except summer"
+var negationPattern = regexp.MustCompile(`\b(not|except|excluding)\b`)
+
+// ParseOfferedTerms extracts the terms mentioned in a catalog offering note,
+// i.e "Fall and spring terms annually." -> [fall spring]. Negated terms are
+// excluded, a note with only negated terms offers every other term, i.e
+// "Not offered in spring." -> [fall summer]
+func ParseOfferedTerms(offered string) []string {
+	offered = strings.ToLower(offered)
+
+	offeredTerms := make(map[string]bool)
+	negatedTerms := make(map[string]bool)
+	for _, clause := range strings.FieldsFunc(offered, func(r rune) bool { return r == '.' || r == ';' }) {
+		negation := len(clause)
+		if loc := negationPattern.FindStringIndex(clause); loc != nil {
+			negation = loc[0]
+		}
+		for _, t := range terms {
+			i := strings.Index(clause, t)
+			switch {
+			case i == -1:
+			case i < negation:
+				offeredTerms[t] = true
+			default:
+				negatedTerms[t] = true
+			}
+		}
+	}
+
+	onlyNegated := len(offeredTerms) == 0 && len(negatedTerms) > 0
+	res := []string{}
+	for _, t := range terms {
+		if negatedTerms[t] {
+			continue
+		}
+		if offeredTerms[t] || onlyNegated {
+			res = append(res, t)
+		}
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+package course
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOfferedTerms(t *testing.T) {
+	tests := []struct {
+		offered  string
+		expected []string
+	}{
+		{"Fall and spring terms annually.", []string{TermFall, TermSpring}},
+		{"Spring term annually.", []string{TermSpring}},
+		{"Fall term odd-numbered years.", []string{TermFall}},
+		{"Fall, spring, and summer terms annually.", []string{TermFall, TermSpring, TermSummer}},
+		{"Summer term annually.", []string{TermSummer}},
+		{"Not offered in spring.", []string{TermFall, TermSummer}},
+		{"Fall term annually; not offered in spring.", []string{TermFall}},
+		{"Offered fall and spring, except summer.", []string{TermFall, TermSpring}},
+		{"Fall term annually. Not offered in summer.", []string{TermFall}},
+		{"Upon availability of instructor.", []string{}},
+		{"", []string{}},
+	}
+
+	for _, test := range tests {
+		actual := ParseOfferedTerms(test.offered)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.offered, test.expected, actual)
+		}
+	}
+}
+
+func TestCourseDB__OfferedIn(t *testing.T) {
+	fall := &CourseDB{Code: "CSCI-4100", Offered: []string{TermFall}}
+	unknown := &CourseDB{Code: "CSCI-4969"}
+
+	tests := []struct {
+		name     string
+		course   *CourseDB
+		term     string
+		expected bool
+	}{
+		{"offered term", fall, TermFall, true},
+		{"other term", fall, TermSpring, false},
+		{"unknown semester term", fall, "", true},
+		{"no offering data", unknown, TermSummer, true},
+	}
+
+	for _, test := range tests {
+		if actual := test.course.OfferedIn(test.term); actual != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package degree
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"github.com/huynchu/degree-planner-api/internal/user"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditTermNotOffered     = "term_not_offered"
+	AuditUnmetRequirement   = "unmet_requirement"
+	AuditStandingRestricted = "class_standing_restricted"
+	AuditMajorRestricted    = "major_restricted"
+	AuditPermissionRequired = "permission_required"
+	AuditRetiredCourse      = "retired_course"
+)
+
+type DegreeAudit struct {
+	DegreeID primitive.ObjectID `json:"degreeID"`
+	Warnings []AuditWarning     `json:"warnings"`
+}
+
+type AuditWarning struct {
+	Type          string             `json:"type"`
+	SemesterIndex int                `json:"semesterIndex"`
+	CourseID      primitive.ObjectID `json:"courseID"`
+	CourseCode    string             `json:"courseCode"`
+	Message       string             `json:"message"`
+}
+
+// AuditDegree checks every planned course of the degree, restrictions are
+// checked against the profile of usr
+func (ds *DegreeService) AuditDegree(degreeID string, usr *user.UserDB) (*DegreeAudit, error) {
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	courses, err := ds.findPlannedCourses(degree)
+	if err != nil {
+		return nil, err
+	}
+
+	equivalences, err := ds.courseService.Equivalences(degree.CatalogYear)
+	if err != nil {
+		return nil, err
+	}
+
+	major := ""
+	if usr != nil {
+		major = usr.Major
+	}
+
+	audit := auditPlan(degree, courses, equivalences, major, time.Now())
+	return &audit, nil
+}
+
+// auditPlan checks every planned course of the degree at the time, restrictions
+// are checked against the major
+func auditPlan(degree *DegreeDB, courses map[primitive.ObjectID]*course.CourseDB, equivalences course.Equivalences, major string, now time.Time) DegreeAudit {
+	unmet := evaluatePlan(degree.PriorCredits, degree.Semesters, courses, equivalences)
+
+	audit := DegreeAudit{
+		DegreeID: degree.ID,
+		Warnings: []AuditWarning{},
+	}
+
+	credits := priorCredits(degree.PriorCredits)
+	for i, semester := range degree.Semesters {
+		term := semesterTerm(semester)
+		standing := course.ClassStanding(credits)
+		future := semesterNotPast(semester, now)
+		for _, courseID := range semester.Courses {
+			c, ok := courses[courseID]
+			if !ok {
+				continue
+			}
+
+			// Check course is offered in the semester's term
+			if !c.OfferedIn(term) {
+				audit.Warnings = append(audit.Warnings, AuditWarning{
+					Type:          AuditTermNotOffered,
+					SemesterIndex: i,
+					CourseID:      c.ID,
+					CourseCode:    c.Code,
+					Message:       fmt.Sprintf("%s is not offered in the %s term", c.Code, term),
+				})
+			}
+
+			// Check course prerequisites and corequisites are satisfied
+			if req, ok := unmet[courseID]; ok {
+				message := fmt.Sprintf("%s %s not satisfied: one of %s is required", c.Code, req.Type, strings.Join(req.Courses, ", "))
+				if req.Expression != "" {
+					message = fmt.Sprintf("%s %s not satisfied: %s is required", c.Code, req.Type, req.Expression)
+				}
+				audit.Warnings = append(audit.Warnings, AuditWarning{
+					Type:          AuditUnmetRequirement,
+					SemesterIndex: i,
+					CourseID:      c.ID,
+					CourseCode:    c.Code,
+					Message:       message,
+				})
+			}
+
+			// Check course is still in the catalog
+			if c.Retired && future {
+				audit.Warnings = append(audit.Warnings, AuditWarning{
+					Type:          AuditRetiredCourse,
+					SemesterIndex: i,
+					CourseID:      c.ID,
+					CourseCode:    c.Code,
+					Message:       fmt.Sprintf("%s was removed from the catalog and is no longer offered", c.Code),
+				})
+			}
+
+			// Check student may register for the course
+			if c.Restrictions != nil {
+				audit.Warnings = append(audit.Warnings, restrictionWarnings(c, i, standing, major)...)
+			}
+		}
+		credits += semesterCredits(semester, courses)
+	}
+
+	return audit
+}
+
+// findPlannedCourses fetches every course planned or credited in the degree keyed by ID
+func (ds *DegreeService) findPlannedCourses(degree *DegreeDB) (map[primitive.ObjectID]*course.CourseDB, error) {
+	ids := []primitive.ObjectID{}
+	for _, semester := range degree.Semesters {
+		ids = append(ids, semester.Courses...)
+	}
+	for _, priorCredit := range degree.PriorCredits {
+		if priorCredit.CourseID != nil {
+			ids = append(ids, *priorCredit.CourseID)
+		}
+	}
+
+	courses, err := ds.courseService.FindCoursesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[primitive.ObjectID]*course.CourseDB, len(courses))
+	for i := range courses {
+		res[courses[i].ID] = &courses[i]
+	}
+	return res, nil
+}
+
+// semesterTerm returns the term of the semester, falling back to the term
+// mentioned in the semester name i.e "Fall 2024"
+func semesterTerm(semester Semester) string {
+	if semester.Term != "" {
+		return semester.Term
+	}
+	terms := course.ParseOfferedTerms(semester.Name)
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return ""
+}
+
+func restrictionWarnings(c *course.CourseDB, semesterIndex int, standing string, major string) []AuditWarning {
+	warnings := []AuditWarning{}
+	if !c.Restrictions.AllowsStanding(standing) {
+		warnings = append(warnings, AuditWarning{
+			Type:          AuditStandingRestricted,
+			SemesterIndex: semesterIndex,
+			CourseID:      c.ID,
+			CourseCode:    c.Code,
+			Message:       fmt.Sprintf("%s is restricted to %s standing and above, student is a %s", c.Code, c.Restrictions.MinClassStanding, standing),
+		})
+	}
+	if !c.Restrictions.AllowsMajor(major) {
+		warnings = append(warnings, AuditWarning{
+			Type:          AuditMajorRestricted,
+			SemesterIndex: semesterIndex,
+			CourseID:      c.ID,
+			CourseCode:    c.Code,
+			Message:       fmt.Sprintf("%s is restricted to %s majors", c.Code, strings.Join(c.Restrictions.Majors, ", ")),
+		})
+	}
+	if c.Restrictions.PermissionRequired {
+		warnings = append(warnings, AuditWarning{
+			Type:          AuditPermissionRequired,
+			SemesterIndex: semesterIndex,
+			CourseID:      c.ID,
+			CourseCode:    c.Code,
+			Message:       fmt.Sprintf("%s requires instructor permission", c.Code),
+		})
+	}
+	return warnings
+}
+
+// priorCredits returns the total credits earned before the first semester
+func priorCredits(priorCredits []PriorCredit) int {
+	total := 0
+	for _, priorCredit := range priorCredits {
+		total += priorCredit.Credits
+	}
+	return total
+}
+
+// semesterCredits returns the credits earned in the semester, failed courses
+// earn no credits
+func semesterCredits(semester Semester, courses map[primitive.ObjectID]*course.CourseDB) int {
+	total := 0
+	for _, courseID := range semester.Courses {
+		c, ok := courses[courseID]
+		if !ok || semester.Grades[courseID.Hex()] == "F" {
+			continue
+		}
+		total += c.CreditsOrDefault()
+	}
+	return total
+}
+
+// Order of the terms within a calendar year
+var termOrder = map[string]int{
+	course.TermSpring: 0,
+	course.TermSummer: 1,
+	course.TermFall:   2,
+}
+
+// semesterNotPast reports whether the semester is the current or a future
+// semester. Semesters without a year or term are assumed to be planned ahead
+func semesterNotPast(semester Semester, now time.Time) bool {
+	term := semesterTerm(semester)
+	if semester.Year == 0 || term == "" {
+		return true
+	}
+	if semester.Year != now.Year() {
+		return semester.Year > now.Year()
+	}
+	return termOrder[term] >= termOrder[currentTerm(now)]
+}
+
+// currentTerm returns the term in session at the time
+func currentTerm(now time.Time) string {
+	switch {
+	case now.Month() <= time.May:
+		return course.TermSpring
+	case now.Month() <= time.July:
+		return course.TermSummer
+	default:
+		return course.TermFall
+	}
+}
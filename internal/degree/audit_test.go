@@ -0,0 +1,155 @@
+package degree
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+)
+
+func TestSemesterTerm(t *testing.T) {
+	tests := []struct {
+		name     string
+		semester Semester
+		expected string
+	}{
+		{"term set", Semester{Name: "Spring 2025", Term: course.TermFall}, course.TermFall},
+		{"term from name", Semester{Name: "Fall 2024"}, course.TermFall},
+		{"lowercase name", Semester{Name: "summer 2025"}, course.TermSummer},
+		{"no term in name", Semester{Name: "Semester 1"}, ""},
+		{"several terms in name", Semester{Name: "Fall/Spring"}, ""},
+	}
+
+	for _, test := range tests {
+		if actual := semesterTerm(test.semester); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestRestrictionWarnings(t *testing.T) {
+	seminar := testCourse("CSCI-4960", "")
+	seminar.Restrictions = &course.CourseRestrictions{MinClassStanding: course.StandingJunior}
+	majors := testCourse("CSCI-4430", "")
+	majors.Restrictions = &course.CourseRestrictions{Majors: []string{"CSCI", "ITWS"}}
+	thesis := testCourse("CSCI-4990", "")
+	thesis.Restrictions = &course.CourseRestrictions{MinClassStanding: course.StandingSenior, Majors: []string{"CSCI"}, PermissionRequired: true}
+
+	tests := []struct {
+		name     string
+		course   *course.CourseDB
+		credits  int
+		major    string
+		expected []string
+	}{
+		{"standing reached", seminar, 60, "CSCI", []string{}},
+		{"standing not reached", seminar, 59, "CSCI", []string{AuditStandingRestricted}},
+		{"major allowed", majors, 0, "ITWS", []string{}},
+		{"major allowed any case", majors, 0, "itws", []string{}},
+		{"major not allowed", majors, 0, "MATH", []string{AuditMajorRestricted}},
+		{"no major", majors, 0, "", []string{AuditMajorRestricted}},
+		{"every restriction", thesis, 30, "MATH", []string{AuditStandingRestricted, AuditMajorRestricted, AuditPermissionRequired}},
+		{"permission only", thesis, 90, "CSCI", []string{AuditPermissionRequired}},
+	}
+
+	for _, test := range tests {
+		warnings := restrictionWarnings(test.course, 2, course.ClassStanding(test.credits), test.major)
+		types := []string{}
+		for _, warning := range warnings {
+			types = append(types, warning.Type)
+			if warning.SemesterIndex != 2 || warning.CourseCode != test.course.Code {
+				t.Errorf("%s: unexpected warning %+v", test.name, warning)
+			}
+		}
+		if !reflect.DeepEqual(types, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, types)
+		}
+	}
+}
+
+func TestSemesterCredits(t *testing.T) {
+	cs1 := testCourse("CSCI-1100", "")
+	cs1.Credits = 4
+	seminar := testCourse("CSCI-1000", "")
+	seminar.Credits = 1
+	unknown := testCourse("CSCI-1960", "")
+	courses, _ := testCatalog(cs1, seminar, unknown)
+
+	semester := testSemester(cs1, seminar, unknown)
+	if credits := semesterCredits(semester, courses); credits != 4+1+course.DefaultCourseCredits {
+		t.Errorf("Expected %v credits, got %v", 4+1+course.DefaultCourseCredits, credits)
+	}
+
+	// Failed courses earn no credits towards class standing
+	semester.Grades = map[string]string{cs1.ID.Hex(): "F", seminar.ID.Hex(): "D"}
+	if credits := semesterCredits(semester, courses); credits != 1+course.DefaultCourseCredits {
+		t.Errorf("Expected %v credits, got %v", 1+course.DefaultCourseCredits, credits)
+	}
+}
+
+func TestSemesterNotPast(t *testing.T) {
+	fall := time.Date(2025, time.October, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		semester Semester
+		now      time.Time
+		expected bool
+	}{
+		{"earlier year", Semester{Term: course.TermFall, Year: 2024}, fall, false},
+		{"later year", Semester{Term: course.TermSpring, Year: 2026}, fall, true},
+		{"earlier term", Semester{Term: course.TermSpring, Year: 2025}, fall, false},
+		{"current term", Semester{Term: course.TermFall, Year: 2025}, fall, true},
+		{"later term", Semester{Term: course.TermFall, Year: 2025}, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), true},
+		{"last day of spring", Semester{Term: course.TermSpring, Year: 2025}, time.Date(2025, time.May, 31, 23, 0, 0, 0, time.UTC), true},
+		{"first day of summer", Semester{Term: course.TermSpring, Year: 2025}, time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), false},
+		{"last day of summer", Semester{Term: course.TermSummer, Year: 2025}, time.Date(2025, time.July, 31, 23, 0, 0, 0, time.UTC), true},
+		{"first day of fall", Semester{Term: course.TermSummer, Year: 2025}, time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC), false},
+		{"term from name", Semester{Name: "Spring 2025", Year: 2025}, fall, false},
+		{"no year", Semester{Term: course.TermSpring}, fall, true},
+		{"no term", Semester{Name: "Semester 1", Year: 2020}, fall, true},
+	}
+
+	for _, test := range tests {
+		if actual := semesterNotPast(test.semester, test.now); actual != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestAuditPlan__RetiredCourses(t *testing.T) {
+	now := time.Date(2025, time.October, 15, 0, 0, 0, 0, time.UTC)
+	retired := testCourse("CSCI-4969", "")
+	retired.Retired = true
+	current := testCourse("CSCI-4430", "")
+	courses, _ := testCatalog(retired, current)
+
+	tests := []struct {
+		name     string
+		semester Semester
+		expected []string
+	}{
+		{"retired in a past semester", Semester{Term: course.TermSpring, Year: 2025}, []string{}},
+		{"retired in the current semester", Semester{Term: course.TermFall, Year: 2025}, []string{"CSCI-4969"}},
+		{"retired in a future semester", Semester{Term: course.TermSpring, Year: 2026}, []string{"CSCI-4969"}},
+		{"retired in an unscheduled semester", Semester{Name: "Semester 8"}, []string{"CSCI-4969"}},
+	}
+
+	for _, test := range tests {
+		semester := testSemester(retired, current)
+		semester.Name, semester.Term, semester.Year = test.semester.Name, test.semester.Term, test.semester.Year
+		degree := &DegreeDB{Semesters: []Semester{semester}}
+
+		audit := auditPlan(degree, courses, course.Equivalences{}, "CSCI", now)
+		codes := []string{}
+		for _, warning := range audit.Warnings {
+			if warning.Type == AuditRetiredCourse {
+				codes = append(codes, warning.CourseCode)
+			}
+		}
+		if !reflect.DeepEqual(codes, test.expected) {
+			t.Errorf("%s: expected retired warnings for %v, got %v", test.name, test.expected, codes)
+		}
+	}
+}
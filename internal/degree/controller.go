@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/huynchu/degree-planner-api/internal/user"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -22,6 +24,8 @@ func NewDegreeController(dsrv *DegreeService) *DegreeController {
 
 type CreateDegreeRequest struct {
 	Name string `json:"name"`
+	// Catalog year to pin the degree to, defaults to the latest catalog year
+	CatalogYear string `json:"catalogYear"`
 }
 
 func (dc *DegreeController) CreateDegree(w http.ResponseWriter, r *http.Request) {
@@ -35,8 +39,12 @@ func (dc *DegreeController) CreateDegree(w http.ResponseWriter, r *http.Request)
 	}
 
 	// create degree
-	id, err := dc.degreeService.CreateDegree(createDegreeReq.Name)
+	id, err := dc.degreeService.CreateDegree(createDegreeReq.Name, createDegreeReq.CatalogYear)
 	if err != nil {
+		if err == ErrInvalidCatalogYear {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		fmt.Println(err)
 		http.Error(w, "database insert error: create degree", http.StatusInternalServerError)
 		return
@@ -78,6 +86,8 @@ func (dc *DegreeController) FindDegreeByID(w http.ResponseWriter, r *http.Reques
 
 type AddSemesterRequest struct {
 	Name  string `json:"name"`
+	Term  string `json:"term"`
+	Year  int    `json:"year"`
 	Index int    `json:"index"`
 }
 
@@ -94,12 +104,16 @@ func (dc *DegreeController) AddSemester(w http.ResponseWriter, r *http.Request)
 	}
 
 	// add semester
-	err = dc.degreeService.AddSemester(degreeID, addSemesterReq.Name, addSemesterReq.Index)
+	err = dc.degreeService.AddSemester(degreeID, addSemesterReq.Name, addSemesterReq.Term, addSemesterReq.Year, addSemesterReq.Index)
 	if err != nil {
 		if err == ErrSemesterIndexOutOfBounds {
 			http.Error(w, "semester index out of bounds", http.StatusBadRequest)
 			return
 		}
+		if err == ErrInvalidSemesterTerm {
+			http.Error(w, "invalid semester term: term must be one of fall, spring or summer", http.StatusBadRequest)
+			return
+		}
 		fmt.Println(err)
 		http.Error(w, "database insert error: add semester", http.StatusInternalServerError)
 		return
@@ -165,6 +179,26 @@ func (dc *DegreeController) MoveSemester(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// preview which courses would break without moving the semester
+	if r.URL.Query().Get("preview") == "true" {
+		impact, err := dc.degreeService.PreviewMoveSemester(degreeID, index, moveSemesterReq.NewIndex)
+		if err != nil {
+			if err == ErrSemesterIndexOutOfBounds {
+				http.Error(w, "semester index out of bounds", http.StatusBadRequest)
+				return
+			}
+			fmt.Println(err)
+			http.Error(w, "database fetch error: preview move semester", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with json
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(impact)
+		return
+	}
+
 	// move semester
 	err = dc.degreeService.MoveSemester(degreeID, index, moveSemesterReq.NewIndex)
 	if err != nil {
@@ -210,7 +244,7 @@ func (dc *DegreeController) AddCourseToSemester(w http.ResponseWriter, r *http.R
 	// add course
 	err = dc.degreeService.AddCourseToSemester(degreeID, semesterIndex, addCourseReq.CourseID)
 	if err != nil {
-		if err == ErrCourseAlreadyExistsInSemester {
+		if err == ErrCourseAlreadyExistsInSemester || err == ErrCourseNotInCatalogYear {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -238,6 +272,26 @@ func (dc *DegreeController) RemoveCourseFromSemester(w http.ResponseWriter, r *h
 		return
 	}
 
+	// preview which courses would break without removing the course
+	if r.URL.Query().Get("preview") == "true" {
+		impact, err := dc.degreeService.PreviewRemoveCourseFromSemester(degreeID, semesterIndex, courseID)
+		if err != nil {
+			if err == ErrCourseDoesNotExistInSemester || err == ErrSemesterIndexOutOfBounds {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			fmt.Println(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with json
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(impact)
+		return
+	}
+
 	// delete course
 	err = dc.degreeService.RemoveCourseFromSemester(degreeID, semesterIndex, courseID)
 	if err != nil {
@@ -250,3 +304,315 @@ func (dc *DegreeController) RemoveCourseFromSemester(w http.ResponseWriter, r *h
 		return
 	}
 }
+
+func (dc *DegreeController) AuditDegree(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	degreeID := chi.URLParam(r, "degreeID")
+
+	// audit degree plan against the authed user's profile
+	usr, _ := user.FromContext(r.Context())
+	audit, err := dc.degreeService.AuditDegree(degreeID, usr)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "degree not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database fetch error: audit degree", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(audit)
+}
+
+func (dc *DegreeController) EligibleCourses(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	degreeID := chi.URLParam(r, "degreeID")
+	semesterIndexStr := chi.URLParam(r, "index")
+
+	// convert index to int
+	semesterIndex, err := strconv.Atoi(semesterIndexStr)
+	if err != nil {
+		http.Error(w, "invalid semester index", http.StatusBadRequest)
+		return
+	}
+
+	// extract query params
+	filter := EligibleFilter{
+		Subject:   strings.ToUpper(r.URL.Query().Get("subject")),
+		Attribute: r.URL.Query().Get("attribute"),
+		Page:      1,
+		Limit:     20,
+	}
+	if levelQuery := r.URL.Query().Get("level"); levelQuery != "" {
+		level, err := strconv.Atoi(levelQuery)
+		if err != nil || level < 1000 || level > 9000 || level%1000 != 0 {
+			http.Error(w, "invalid level param: level must be one of 1000, 2000, ..., 9000", http.StatusBadRequest)
+			return
+		}
+		filter.Level = level
+	}
+	if pageQuery := r.URL.Query().Get("page"); pageQuery != "" {
+		page, err := strconv.Atoi(pageQuery)
+		if err != nil || page <= 0 {
+			http.Error(w, "invalid page param: page must be greater than 0", http.StatusBadRequest)
+			return
+		}
+		filter.Page = page
+	}
+	maxLimit := 100
+	if limitQuery := r.URL.Query().Get("limit"); limitQuery != "" {
+		limit, err := strconv.Atoi(limitQuery)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			http.Error(w, fmt.Sprintf("invalid limit param: limit must be greater than 0 and less than %v", maxLimit), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	// find eligible courses
+	eligible, err := dc.degreeService.EligibleCourses(degreeID, semesterIndex, filter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "degree not found", http.StatusNotFound)
+			return
+		}
+		if err == ErrSemesterIndexOutOfBounds {
+			http.Error(w, "semester index out of bounds", http.StatusBadRequest)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database fetch error: eligible courses", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(eligible)
+}
+
+type RecordGradeRequest struct {
+	Grade string `json:"grade"`
+}
+
+func (dc *DegreeController) RecordGrade(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	degreeID := chi.URLParam(r, "degreeID")
+	semesterIndexStr := chi.URLParam(r, "index")
+	courseID := chi.URLParam(r, "courseID")
+
+	// convert index to int
+	semesterIndex, err := strconv.Atoi(semesterIndexStr)
+	if err != nil {
+		http.Error(w, "invalid semester index", http.StatusBadRequest)
+		return
+	}
+
+	// decode json body
+	var recordGradeReq RecordGradeRequest
+	err = json.NewDecoder(r.Body).Decode(&recordGradeReq)
+	if err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	// record grade
+	err = dc.degreeService.RecordGrade(degreeID, semesterIndex, courseID, strings.ToUpper(recordGradeReq.Grade))
+	if err != nil {
+		if err == ErrSemesterIndexOutOfBounds || err == ErrCourseDoesNotExistInSemester || err == ErrInvalidGrade {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "degree not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database update error: record grade", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode("recorded grade successfully")
+}
+
+type PriorCreditRequest struct {
+	Source      string `json:"source"`
+	CourseID    string `json:"courseID"`
+	Description string `json:"description"`
+	Credits     int    `json:"credits"`
+}
+
+func (dc *DegreeController) FindPriorCredits(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	degreeID := chi.URLParam(r, "degreeID")
+
+	// fetch prior credits
+	priorCredits, err := dc.degreeService.FindPriorCredits(degreeID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "degree not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database fetch error: fetch prior credits", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(priorCredits)
+}
+
+func (dc *DegreeController) AddPriorCredit(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	degreeID := chi.URLParam(r, "degreeID")
+
+	// decode json body
+	var priorCreditReq PriorCreditRequest
+	err := json.NewDecoder(r.Body).Decode(&priorCreditReq)
+	if err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	// add prior credit
+	id, err := dc.degreeService.AddPriorCredit(degreeID, priorCreditReq.Source, priorCreditReq.CourseID, priorCreditReq.Description, priorCreditReq.Credits)
+	if err != nil {
+		if err == ErrInvalidPriorCreditSource || err == ErrInvalidPriorCredit || err == ErrCourseNotInCatalogYear {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "degree or course not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database insert error: add prior credit", http.StatusInternalServerError)
+		return
+	}
+
+	// encode json response
+	res := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (dc *DegreeController) UpdatePriorCredit(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	degreeID := chi.URLParam(r, "degreeID")
+	priorCreditID := chi.URLParam(r, "priorCreditID")
+
+	// decode json body
+	var priorCreditReq PriorCreditRequest
+	err := json.NewDecoder(r.Body).Decode(&priorCreditReq)
+	if err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	// update prior credit
+	err = dc.degreeService.UpdatePriorCredit(degreeID, priorCreditID, priorCreditReq.Source, priorCreditReq.CourseID, priorCreditReq.Description, priorCreditReq.Credits)
+	if err != nil {
+		if err == ErrInvalidPriorCreditSource || err == ErrInvalidPriorCredit || err == ErrCourseNotInCatalogYear {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == ErrPriorCreditNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "degree or course not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database update error: update prior credit", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode("updated prior credit successfully")
+}
+
+func (dc *DegreeController) RemovePriorCredit(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	degreeID := chi.URLParam(r, "degreeID")
+	priorCreditID := chi.URLParam(r, "priorCreditID")
+
+	// remove prior credit
+	err := dc.degreeService.RemovePriorCredit(degreeID, priorCreditID)
+	if err != nil {
+		if err == ErrPriorCreditNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "degree not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database delete error: remove prior credit", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode("removed prior credit successfully")
+}
+
+type MigrateCatalogYearRequest struct {
+	CatalogYear string `json:"catalogYear"`
+}
+
+func (dc *DegreeController) MigrateCatalogYear(w http.ResponseWriter, r *http.Request) {
+	// extract url params
+	degreeID := chi.URLParam(r, "degreeID")
+
+	// decode json body
+	var migrateReq MigrateCatalogYearRequest
+	err := json.NewDecoder(r.Body).Decode(&migrateReq)
+	if err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	// migrate degree, a preview only reports the course mapping
+	preview := r.URL.Query().Get("preview") == "true"
+	migration, err := dc.degreeService.MigrateCatalogYear(degreeID, migrateReq.CatalogYear, preview)
+	if err != nil {
+		if err == ErrInvalidCatalogYear || err == ErrCatalogYearNotNewer || err == ErrCatalogYearNotFound {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "degree not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println(err)
+		http.Error(w, "database update error: migrate catalog year", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(migration)
+}
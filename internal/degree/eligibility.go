@@ -0,0 +1,90 @@
+package degree
+
+import (
+	"github.com/huynchu/degree-planner-api/internal/course"
+)
+
+type EligibleFilter struct {
+	Subject string
+	Level   int
+	// Catalog attribute, i.e Communication Intensive
+	Attribute string
+	Page      int
+	Limit     int
+}
+
+type EligibleCourses struct {
+	Courses []course.CourseDB `json:"courses"`
+	Page    int               `json:"page"`
+	Limit   int               `json:"limit"`
+	Total   int               `json:"total"`
+}
+
+// EligibleCourses returns the courses that can be taken in the semester: every
+// prerequisite is satisfied by earlier semesters and the course is not planned yet
+func (ds *DegreeService) EligibleCourses(degreeID string, semesterIndex int, filter EligibleFilter) (*EligibleCourses, error) {
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if semesterIndex < 0 || semesterIndex >= len(degree.Semesters) {
+		return nil, ErrSemesterIndexOutOfBounds
+	}
+
+	planned, err := ds.findPlannedCourses(degree)
+	if err != nil {
+		return nil, err
+	}
+	equivalences, err := ds.courseService.Equivalences(degree.CatalogYear)
+	if err != nil {
+		return nil, err
+	}
+	state := stateAt(degree, planned, equivalences, semesterIndex)
+	plannedCodes := plannedCodes(planned, equivalences)
+
+	candidates, err := ds.courseService.FindCourses(degree.CatalogYear, filter.Subject, filter.Level, filter.Attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := eligibleCourses(state, plannedCodes, candidates)
+	res := paginateEligible(eligible, filter)
+	return &res, nil
+}
+
+// eligibleCourses returns the candidates that are not planned yet and whose
+// prerequisites are satisfied in the plan state
+func eligibleCourses(state *planState, plannedCodes map[string]bool, candidates []course.CourseDB) []course.CourseDB {
+	eligible := []course.CourseDB{}
+	for i := range candidates {
+		c := &candidates[i]
+		if plannedCodes[c.Code] {
+			continue
+		}
+		if !state.prerequisitesSatisfied(c) {
+			continue
+		}
+		eligible = append(eligible, *c)
+	}
+	return eligible
+}
+
+// paginateEligible returns the page of the filter, pages start at 1
+func paginateEligible(eligible []course.CourseDB, filter EligibleFilter) EligibleCourses {
+	res := EligibleCourses{
+		Courses: []course.CourseDB{},
+		Page:    filter.Page,
+		Limit:   filter.Limit,
+		Total:   len(eligible),
+	}
+	start := (filter.Page - 1) * filter.Limit
+	if start < len(eligible) {
+		end := start + filter.Limit
+		if end > len(eligible) {
+			end = len(eligible)
+		}
+		res.Courses = eligible[start:end]
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package degree
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+)
+
+func TestEligibleCourses(t *testing.T) {
+	cs1 := testCourse("CSCI-1100", "")
+	cs2 := testCourse("CSCI-1200", "CSCI-1100")
+	algo := testCourse("CSCI-2300", "CSCI-1200 and MATH-1010")
+	calc := testCourse("MATH-1010", "")
+	opsys := testCourse("CSCI-4210", "CSCI-2300")
+	courses, _ := testCatalog(cs1, cs2, calc)
+
+	degree := &DegreeDB{Semesters: []Semester{testSemester(cs1, calc), testSemester(cs2), testSemester()}}
+	candidates := []course.CourseDB{*cs1, *cs2, *algo, *calc, *opsys}
+
+	tests := []struct {
+		name          string
+		semesterIndex int
+		expected      []string
+	}{
+		{"first semester", 0, []string{}},
+		{"prerequisites in earlier semesters", 1, []string{}},
+		{"prerequisites taken", 2, []string{"CSCI-2300"}},
+	}
+
+	for _, test := range tests {
+		state := stateAt(degree, courses, course.Equivalences{}, test.semesterIndex)
+		eligible := eligibleCourses(state, plannedCodes(courses, course.Equivalences{}), candidates)
+		codes := []string{}
+		for _, c := range eligible {
+			codes = append(codes, c.Code)
+		}
+		if !reflect.DeepEqual(codes, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, codes)
+		}
+	}
+}
+
+func TestPaginateEligible(t *testing.T) {
+	eligible := []course.CourseDB{}
+	for _, code := range []string{"CSCI-1100", "CSCI-1200", "CSCI-2200", "CSCI-2300", "CSCI-2500"} {
+		eligible = append(eligible, *testCourse(code, ""))
+	}
+
+	tests := []struct {
+		name     string
+		filter   EligibleFilter
+		expected []string
+	}{
+		{"first page", EligibleFilter{Page: 1, Limit: 2}, []string{"CSCI-1100", "CSCI-1200"}},
+		{"middle page", EligibleFilter{Page: 2, Limit: 2}, []string{"CSCI-2200", "CSCI-2300"}},
+		{"partial last page", EligibleFilter{Page: 3, Limit: 2}, []string{"CSCI-2500"}},
+		{"past the last page", EligibleFilter{Page: 4, Limit: 2}, []string{}},
+		{"limit over total", EligibleFilter{Page: 1, Limit: 20}, []string{"CSCI-1100", "CSCI-1200", "CSCI-2200", "CSCI-2300", "CSCI-2500"}},
+	}
+
+	for _, test := range tests {
+		res := paginateEligible(eligible, test.filter)
+		if res.Total != len(eligible) || res.Page != test.filter.Page || res.Limit != test.filter.Limit {
+			t.Errorf("%s: expected total %v, page %v and limit %v, got %+v", test.name, len(eligible), test.filter.Page, test.filter.Limit, res)
+		}
+		if len(res.Courses) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v courses", test.name, test.expected, len(res.Courses))
+			continue
+		}
+		for i, c := range res.Courses {
+			if c.Code != test.expected[i] {
+				t.Errorf("%s: expected %v, got %v at %v", test.name, test.expected[i], c.Code, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package degree
+
+import (
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"github.com/huynchu/degree-planner-api/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The courses of a plan that would lose a satisfied requirement because of a change
+type ChangeImpact struct {
+	Affected []AffectedCourse `json:"affected"`
+}
+
+type AffectedCourse struct {
+	SemesterIndex int                `json:"semesterIndex"`
+	CourseID      primitive.ObjectID `json:"courseID"`
+	CourseCode    string             `json:"courseCode"`
+	Requirement   UnmetRequirement   `json:"requirement"`
+}
+
+func (ds *DegreeService) PreviewRemoveCourseFromSemester(degreeID string, semesterIndex int, courseID string) (*ChangeImpact, error) {
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	after := append([]Semester{}, degree.Semesters...)
+	after, err = removeCourse(after, semesterIndex, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.changeImpact(degree, after)
+}
+
+func (ds *DegreeService) PreviewMoveSemester(degreeID string, semesterIndex int, newIndex int) (*ChangeImpact, error) {
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := moveSemester(degree.Semesters, semesterIndex, newIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.changeImpact(degree, after)
+}
+
+// changeImpact compares the plan before and after a change and returns every
+// course whose requirements were satisfied before but not after
+func (ds *DegreeService) changeImpact(degree *DegreeDB, after []Semester) (*ChangeImpact, error) {
+	courses, err := ds.findPlannedCourses(degree)
+	if err != nil {
+		return nil, err
+	}
+
+	equivalences, err := ds.courseService.Equivalences(degree.CatalogYear)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := planChangeImpact(degree, after, courses, equivalences)
+	return &impact, nil
+}
+
+// planChangeImpact returns every course of the semesters after the change
+// whose requirements were satisfied before the change but not after
+func planChangeImpact(degree *DegreeDB, after []Semester, courses map[primitive.ObjectID]*course.CourseDB, equivalences course.Equivalences) ChangeImpact {
+	unmetBefore := evaluatePlan(degree.PriorCredits, degree.Semesters, courses, equivalences)
+	unmetAfter := evaluatePlan(degree.PriorCredits, after, courses, equivalences)
+
+	impact := ChangeImpact{
+		Affected: []AffectedCourse{},
+	}
+	for i, semester := range after {
+		for _, courseID := range semester.Courses {
+			req, broken := unmetAfter[courseID]
+			if !broken {
+				continue
+			}
+			if _, wasBroken := unmetBefore[courseID]; wasBroken {
+				continue
+			}
+			impact.Affected = append(impact.Affected, AffectedCourse{
+				SemesterIndex: i,
+				CourseID:      courseID,
+				CourseCode:    courses[courseID].Code,
+				Requirement:   req,
+			})
+		}
+	}
+
+	return impact
+}
+
+// removeCourse removes the course from the semester, the semesters slice is
+// modified but the courses of other semesters are left untouched
+func removeCourse(semesters []Semester, semesterIndex int, courseID string) ([]Semester, error) {
+	// Check if semester exists
+	if semesterIndex < 0 || semesterIndex >= len(semesters) {
+		return nil, ErrSemesterIndexOutOfBounds
+	}
+
+	// Remove course from semester
+	semester := &semesters[semesterIndex]
+	courseIndex := -1
+	for i, course := range semester.Courses {
+		if course.Hex() == courseID {
+			courseIndex = i
+			break
+		}
+	}
+	if courseIndex == -1 {
+		return nil, ErrCourseDoesNotExistInSemester
+	}
+	semester.Courses = utils.Remove(semester.Courses, courseIndex)
+
+	// Drop the recorded grade, the map is copied since it may be shared
+	if _, ok := semester.Grades[courseID]; ok {
+		grades := make(map[string]string, len(semester.Grades))
+		for id, grade := range semester.Grades {
+			if id != courseID {
+				grades[id] = grade
+			}
+		}
+		semester.Grades = grades
+	}
+
+	return semesters, nil
+}
+
+// moveSemester returns a copy of the semesters with the semester moved to newIndex
+func moveSemester(semesters []Semester, semesterIndex int, newIndex int) ([]Semester, error) {
+	if semesterIndex < 0 || semesterIndex >= len(semesters) ||
+		newIndex < 0 || newIndex >= len(semesters) {
+		return nil, ErrSemesterIndexOutOfBounds
+	}
+
+	return utils.Move(semesters, semesterIndex, newIndex), nil
+}
@@ -0,0 +1,117 @@
+package degree
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+)
+
+func TestPlanChangeImpact(t *testing.T) {
+	cs1 := testCourse("CSCI-1100", "")
+	calc := testCourse("MATH-1010", "")
+	cs2 := testCourse("CSCI-1200", "CSCI-1100")
+	algo := testCourse("CSCI-2300", "CSCI-1200 and MATH-1010")
+	opsys := testCourse("CSCI-4210", "CSCI-2300")
+	broken := testCourse("CSCI-4430", "CSCI-4969")
+	courses, _ := testCatalog(cs1, calc, cs2, algo, opsys, broken)
+
+	newDegree := func() *DegreeDB {
+		return &DegreeDB{Semesters: []Semester{
+			testSemester(cs1, calc),
+			testSemester(cs2),
+			testSemester(algo),
+			testSemester(opsys, broken),
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		change   func(semesters []Semester) ([]Semester, error)
+		expected []string
+	}{
+		{"remove transitive prerequisite", func(semesters []Semester) ([]Semester, error) {
+			return removeCourse(semesters, 0, cs1.ID.Hex())
+		}, []string{"CSCI-1200", "CSCI-2300", "CSCI-4210"}},
+		{"remove direct prerequisite", func(semesters []Semester) ([]Semester, error) {
+			return removeCourse(semesters, 1, cs2.ID.Hex())
+		}, []string{"CSCI-2300", "CSCI-4210"}},
+		{"remove course without dependents", func(semesters []Semester) ([]Semester, error) {
+			return removeCourse(semesters, 3, opsys.ID.Hex())
+		}, []string{}},
+		{"move semester before its prerequisites", func(semesters []Semester) ([]Semester, error) {
+			return moveSemester(semesters, 2, 0)
+		}, []string{"CSCI-2300", "CSCI-4210"}},
+		{"move semester later", func(semesters []Semester) ([]Semester, error) {
+			return moveSemester(semesters, 0, 1)
+		}, []string{"CSCI-1200", "CSCI-2300", "CSCI-4210"}},
+		{"move semester to the same index", func(semesters []Semester) ([]Semester, error) {
+			return moveSemester(semesters, 3, 3)
+		}, []string{}},
+	}
+
+	for _, test := range tests {
+		degree := newDegree()
+		after, err := test.change(append([]Semester{}, degree.Semesters...))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		impact := planChangeImpact(degree, after, courses, course.Equivalences{})
+		codes := []string{}
+		for _, affected := range impact.Affected {
+			codes = append(codes, affected.CourseCode)
+		}
+		if !reflect.DeepEqual(codes, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, codes)
+		}
+	}
+}
+
+func TestPlanChangeImpact__CrossListed(t *testing.T) {
+	cs1 := testCourse("CSCI-1100", "")
+	itws := testCourse("ITWS-1100", "")
+	cs2 := testCourse("CSCI-1200", "CSCI-1100")
+	courses, _ := testCatalog(cs1, itws, cs2)
+	equivalences := course.Equivalences{
+		"CSCI-1100": {"CSCI-1100", "ITWS-1100"},
+		"ITWS-1100": {"CSCI-1100", "ITWS-1100"},
+	}
+
+	// The cross-listed course still satisfies the prerequisite
+	degree := &DegreeDB{Semesters: []Semester{testSemester(cs1, itws), testSemester(cs2)}}
+	after, err := removeCourse(append([]Semester{}, degree.Semesters...), 0, cs1.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	impact := planChangeImpact(degree, after, courses, equivalences)
+	if len(impact.Affected) != 0 {
+		t.Errorf("Expected no affected courses, got %+v", impact.Affected)
+	}
+}
+
+func TestRemoveCourse__KeepsOriginal(t *testing.T) {
+	cs1 := testCourse("CSCI-1100", "")
+	calc := testCourse("MATH-1010", "")
+	semester := testSemester(cs1, calc)
+	semester.Grades = map[string]string{cs1.ID.Hex(): "A", calc.ID.Hex(): "B"}
+	semesters := []Semester{semester}
+
+	after, err := removeCourse(append([]Semester{}, semesters...), 0, cs1.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after[0].Courses) != 1 || len(after[0].Grades) != 1 {
+		t.Errorf("Expected the course and its grade removed, got %+v", after[0])
+	}
+	if len(semesters[0].Courses) != 2 || len(semesters[0].Grades) != 2 {
+		t.Errorf("Expected the original semester untouched, got %+v", semesters[0])
+	}
+
+	if _, err := removeCourse(semesters, 0, calc.ID.Hex()+"0"); err != ErrCourseDoesNotExistInSemester {
+		t.Errorf("Expected %v, got %v", ErrCourseDoesNotExistInSemester, err)
+	}
+	if _, err := removeCourse(semesters, 1, calc.ID.Hex()); err != ErrSemesterIndexOutOfBounds {
+		t.Errorf("Expected %v, got %v", ErrSemesterIndexOutOfBounds, err)
+	}
+}
@@ -0,0 +1,164 @@
+package degree
+
+import (
+	"errors"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCatalogYearNotNewer = errors.New("catalog year must be newer than the degree catalog year")
+	ErrCatalogYearNotFound = errors.New("catalog year has not been ingested")
+)
+
+// The result of bumping a degree to a newer catalog year. Unmapped courses do
+// not exist in the new catalog and keep referencing the old catalog course
+type CatalogYearMigration struct {
+	DegreeID     primitive.ObjectID `json:"degreeID"`
+	FromYear     string             `json:"fromYear"`
+	ToYear       string             `json:"toYear"`
+	Preview      bool               `json:"preview"`
+	Mapped       []string           `json:"mapped"`
+	Unmapped     []string           `json:"unmapped"`
+	Semesters    []Semester         `json:"semesters"`
+	PriorCredits []PriorCredit      `json:"priorCredits"`
+}
+
+// MigrateCatalogYear pins the degree to a newer catalog year and remaps every
+// planned course, recorded grade and prior credit course to the course with the
+// same code in that year. A preview computes the migration without saving it
+func (ds *DegreeService) MigrateCatalogYear(degreeID string, catalogYear string, preview bool) (*CatalogYearMigration, error) {
+	if !course.IsValidCatalogYear(catalogYear) {
+		return nil, ErrInvalidCatalogYear
+	}
+
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	years, err := ds.courseService.CatalogYears()
+	if err != nil {
+		return nil, err
+	}
+	err = checkMigrationYear(degree.CatalogYear, catalogYear, years)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map old course IDs to new course IDs by code
+	planned, err := ds.findPlannedCourses(degree)
+	if err != nil {
+		return nil, err
+	}
+	codes := []string{}
+	for _, c := range planned {
+		codes = append(codes, c.Code)
+	}
+	newCourses, err := ds.courseService.FindCoursesByCodes(catalogYear, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	migration := migratePlan(degree, catalogYear, planned, newCourses)
+	migration.Preview = preview
+	if preview {
+		return &migration, nil
+	}
+
+	err = ds.degreeStorage.UpdateCatalogYear(degreeID, catalogYear, migration.Semesters, migration.PriorCredits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migration, nil
+}
+
+// checkMigrationYear returns an error unless the catalog year was ingested and
+// is newer than the degree catalog year, an unpinned degree can move to any year
+func checkMigrationYear(from string, to string, years []string) error {
+	// Catalog years are formatted as 2024-2025 so they compare as strings
+	if from != "" && to <= from {
+		return ErrCatalogYearNotNewer
+	}
+	for _, year := range years {
+		if year == to {
+			return nil
+		}
+	}
+	return ErrCatalogYearNotFound
+}
+
+// migratePlan remaps the planned courses, recorded grades and prior credit
+// courses of the degree to the new courses with the same code
+func migratePlan(degree *DegreeDB, catalogYear string, planned map[primitive.ObjectID]*course.CourseDB, newCourses []course.CourseDB) CatalogYearMigration {
+	byCode := make(map[string]primitive.ObjectID, len(newCourses))
+	for _, c := range newCourses {
+		byCode[c.Code] = c.ID
+	}
+
+	migration := CatalogYearMigration{
+		DegreeID:     degree.ID,
+		FromYear:     degree.CatalogYear,
+		ToYear:       catalogYear,
+		Mapped:       []string{},
+		Unmapped:     []string{},
+		Semesters:    []Semester{},
+		PriorCredits: []PriorCredit{},
+	}
+	seen := make(map[primitive.ObjectID]bool)
+	remap := func(id primitive.ObjectID) primitive.ObjectID {
+		c, ok := planned[id]
+		if !ok {
+			return id
+		}
+		newID, ok := byCode[c.Code]
+		if !seen[id] {
+			seen[id] = true
+			if ok {
+				migration.Mapped = append(migration.Mapped, c.Code)
+			} else {
+				migration.Unmapped = append(migration.Unmapped, c.Code)
+			}
+		}
+		if !ok {
+			return id
+		}
+		return newID
+	}
+
+	for _, semester := range degree.Semesters {
+		migrated := Semester{
+			Name:    semester.Name,
+			Term:    semester.Term,
+			Year:    semester.Year,
+			Courses: []primitive.ObjectID{},
+		}
+		for _, id := range semester.Courses {
+			migrated.Courses = append(migrated.Courses, remap(id))
+		}
+		if len(semester.Grades) > 0 {
+			migrated.Grades = make(map[string]string, len(semester.Grades))
+			for id, grade := range semester.Grades {
+				objID, err := primitive.ObjectIDFromHex(id)
+				if err != nil {
+					migrated.Grades[id] = grade
+					continue
+				}
+				migrated.Grades[remap(objID).Hex()] = grade
+			}
+		}
+		migration.Semesters = append(migration.Semesters, migrated)
+	}
+
+	for _, priorCredit := range degree.PriorCredits {
+		if priorCredit.CourseID != nil {
+			id := remap(*priorCredit.CourseID)
+			priorCredit.CourseID = &id
+		}
+		migration.PriorCredits = append(migration.PriorCredits, priorCredit)
+	}
+
+	return migration
+}
@@ -0,0 +1,88 @@
+package degree
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckMigrationYear(t *testing.T) {
+	years := []string{"2023-2024", "2024-2025", "2025-2026"}
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		err  error
+	}{
+		{"newer year", "2023-2024", "2025-2026", nil},
+		{"unpinned degree", "", "2023-2024", nil},
+		{"same year", "2024-2025", "2024-2025", ErrCatalogYearNotNewer},
+		{"older year", "2025-2026", "2023-2024", ErrCatalogYearNotNewer},
+		{"year not ingested", "2024-2025", "2026-2027", ErrCatalogYearNotFound},
+	}
+
+	for _, test := range tests {
+		if err := checkMigrationYear(test.from, test.to, years); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestMigratePlan(t *testing.T) {
+	cs1 := testCourse("CSCI-1100", "")
+	cs2 := testCourse("CSCI-1200", "CSCI-1100")
+	topics := testCourse("CSCI-4969", "")
+	calc := testCourse("MATH-1010", "")
+	planned, _ := testCatalog(cs1, cs2, topics, calc)
+
+	// CSCI-1200 is renamed in the new catalog and CSCI-4969 was dropped
+	newCS1 := course.CourseDB{ID: primitive.NewObjectID(), Code: "CSCI-1100", Name: "Computer Science I"}
+	newCS2 := course.CourseDB{ID: primitive.NewObjectID(), Code: "CSCI-1200", Name: "Data Structures and Algorithms"}
+	newCalc := course.CourseDB{ID: primitive.NewObjectID(), Code: "MATH-1010", Name: "Calculus I"}
+	newCourses := []course.CourseDB{newCS1, newCS2, newCalc}
+
+	first := testSemester(cs1)
+	first.Name, first.Term, first.Year = "Fall 2024", course.TermFall, 2024
+	first.Grades = map[string]string{cs1.ID.Hex(): "A"}
+	second := testSemester(cs2, topics)
+	second.Grades = map[string]string{cs2.ID.Hex(): "B+", topics.ID.Hex(): "C"}
+	degree := &DegreeDB{
+		ID:           primitive.NewObjectID(),
+		CatalogYear:  "2024-2025",
+		Semesters:    []Semester{first, second},
+		PriorCredits: []PriorCredit{{Source: PriorCreditAP, CourseID: &calc.ID, Credits: 4}, {Source: PriorCreditAP, Description: "AP Art", Credits: 4}},
+	}
+
+	migration := migratePlan(degree, "2025-2026", planned, newCourses)
+
+	tests := []struct {
+		name     string
+		actual   interface{}
+		expected interface{}
+	}{
+		{"years", []string{migration.FromYear, migration.ToYear}, []string{"2024-2025", "2025-2026"}},
+		{"mapped courses", migration.Mapped, []string{"CSCI-1100", "CSCI-1200", "MATH-1010"}},
+		{"unmapped courses are reported", migration.Unmapped, []string{"CSCI-4969"}},
+		{"first semester courses", migration.Semesters[0].Courses, []primitive.ObjectID{newCS1.ID}},
+		{"renamed course is remapped and unmapped course is kept", migration.Semesters[1].Courses, []primitive.ObjectID{newCS2.ID, topics.ID}},
+		{"first semester grades follow the new IDs", migration.Semesters[0].Grades, map[string]string{newCS1.ID.Hex(): "A"}},
+		{"second semester grades follow the new IDs", migration.Semesters[1].Grades, map[string]string{newCS2.ID.Hex(): "B+", topics.ID.Hex(): "C"}},
+		{"semester fields are kept", []interface{}{migration.Semesters[0].Name, migration.Semesters[0].Term, migration.Semesters[0].Year}, []interface{}{"Fall 2024", course.TermFall, 2024}},
+		{"prior credit course is remapped", *migration.PriorCredits[0].CourseID, newCalc.ID},
+		{"prior credit without course is kept", migration.PriorCredits[1], degree.PriorCredits[1]},
+	}
+
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.actual, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, test.actual)
+		}
+	}
+
+	// The degree itself is untouched
+	if degree.Semesters[0].Courses[0] != cs1.ID || *degree.PriorCredits[0].CourseID != calc.ID {
+		t.Errorf("Expected the original degree untouched, got %+v", degree)
+	}
+}
@@ -0,0 +1,210 @@
+package degree
+
+import (
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RequirementPrerequisite = "prerequisite"
+	RequirementCorequisite  = "corequisite"
+)
+
+// An unmet requirement of a planned course, one of Courses must be taken or
+// the Expression must be satisfied
+type UnmetRequirement struct {
+	Type       string   `json:"type"`
+	Courses    []string `json:"courses"`
+	Expression string   `json:"expression,omitempty"`
+}
+
+// planState tracks the courses a student has credit for at a point in the
+// plan. Every course is tracked by code along with its cross-listed equivalents
+type planState struct {
+	courses      map[primitive.ObjectID]*course.CourseDB
+	equivalences course.Equivalences
+
+	// Courses credited before the current semester
+	taken map[string]bool
+	// Recorded grades of taken courses, missing if not graded yet
+	grades map[string]string
+	// Courses planned in the current semester
+	current map[string]bool
+}
+
+// newPlanState returns the state before the first semester, the prior
+// credits are taken
+func newPlanState(priorCredits []PriorCredit, courses map[primitive.ObjectID]*course.CourseDB, equivalences course.Equivalences) *planState {
+	s := &planState{
+		courses:      courses,
+		equivalences: equivalences,
+		taken:        make(map[string]bool),
+		grades:       make(map[string]string),
+		current:      make(map[string]bool),
+	}
+	for _, priorCredit := range priorCredits {
+		if priorCredit.CourseID == nil {
+			continue
+		}
+		if c, ok := courses[*priorCredit.CourseID]; ok {
+			s.take(c.Code, "")
+		}
+	}
+	return s
+}
+
+// take marks the course and its equivalents as taken with the grade. A retake
+// keeps the best grade, a grade that isn't recorded yet is assumed to pass so
+// it replaces the grade of an earlier attempt
+func (s *planState) take(code string, grade string) {
+	codes := []string{code}
+	codes = append(codes, s.equivalences[code]...)
+	for _, c := range codes {
+		previous, graded := s.grades[c]
+		switch {
+		case s.taken[c] && !graded:
+			// Already taken without a grade
+		case grade == "":
+			delete(s.grades, c)
+		case !graded || course.HigherGrade(grade, previous):
+			s.grades[c] = grade
+		}
+	}
+	s.equivalences.Mark(s.taken, code)
+}
+
+// enter sets the semester as the current semester
+func (s *planState) enter(semester Semester) {
+	s.current = make(map[string]bool)
+	for _, courseID := range semester.Courses {
+		if c, ok := s.courses[courseID]; ok {
+			s.equivalences.Mark(s.current, c.Code)
+		}
+	}
+}
+
+// takeSemester marks every course of the semester as taken
+func (s *planState) takeSemester(semester Semester) {
+	for _, courseID := range semester.Courses {
+		if c, ok := s.courses[courseID]; ok {
+			s.take(c.Code, semester.Grades[courseID.Hex()])
+		}
+	}
+}
+
+// leafSatisfied reports whether a single course prerequisite is satisfied: the
+// course was taken with the minimum grade, or is allowed to be taken
+// concurrently and is planned in the current semester
+func (s *planState) leafSatisfied(leaf *course.PrerequisiteExpr) bool {
+	if s.taken[leaf.Course] && course.MeetsMinGrade(s.grades[leaf.Course], leaf.MinGrade) {
+		return true
+	}
+	return leaf.Concurrent && s.current[leaf.Course]
+}
+
+// prerequisitesSatisfied reports whether the prerequisite expression of the
+// course is satisfied, courses without an expression fall back to requiring
+// every prerequisite group to have at least one passed course
+func (s *planState) prerequisitesSatisfied(c *course.CourseDB) bool {
+	_, unmet := s.unmetPrerequisite(c)
+	return !unmet
+}
+
+func (s *planState) unmetPrerequisite(c *course.CourseDB) (UnmetRequirement, bool) {
+	if c.PrerequisiteTree != nil {
+		if !c.PrerequisiteTree.Evaluate(s.leafSatisfied) {
+			return UnmetRequirement{
+				Type:       RequirementPrerequisite,
+				Courses:    c.PrerequisiteTree.Courses(),
+				Expression: c.PrerequisiteTree.String(),
+			}, true
+		}
+		return UnmetRequirement{}, false
+	}
+	for _, group := range c.Prerequisites {
+		if !s.groupSatisfied(group) {
+			return UnmetRequirement{Type: RequirementPrerequisite, Courses: group}, true
+		}
+	}
+	return UnmetRequirement{}, false
+}
+
+// unmetRequirement returns the first unmet prerequisite or corequisite of the
+// course, corequisites may be taken before or in the current semester
+func (s *planState) unmetRequirement(c *course.CourseDB) (UnmetRequirement, bool) {
+	if req, unmet := s.unmetPrerequisite(c); unmet {
+		return req, true
+	}
+	for _, coreq := range c.Corequisites {
+		if !s.taken[coreq] && !s.current[coreq] {
+			return UnmetRequirement{Type: RequirementCorequisite, Courses: []string{coreq}}, true
+		}
+	}
+	return UnmetRequirement{}, false
+}
+
+// groupSatisfied reports whether one course of a flat prerequisite group is
+// satisfied, a failing grade doesn't count as taking the course
+func (s *planState) groupSatisfied(group []string) bool {
+	for _, code := range group {
+		if s.leafSatisfied(&course.PrerequisiteExpr{Type: course.PrerequisiteCourse, Course: code}) {
+			return true
+		}
+	}
+	return false
+}
+
+// stateAt returns the plan state with every semester before semesterIndex
+// taken and semesterIndex as the current semester
+func stateAt(degree *DegreeDB, courses map[primitive.ObjectID]*course.CourseDB, equivalences course.Equivalences, semesterIndex int) *planState {
+	s := newPlanState(degree.PriorCredits, courses, equivalences)
+	for i := 0; i < semesterIndex && i < len(degree.Semesters); i++ {
+		s.takeSemester(degree.Semesters[i])
+	}
+	if semesterIndex < len(degree.Semesters) {
+		s.enter(degree.Semesters[semesterIndex])
+	}
+	return s
+}
+
+// plannedCodes returns the codes of every course credited or planned in the degree,
+// including their cross-listed equivalents
+func plannedCodes(courses map[primitive.ObjectID]*course.CourseDB, equivalences course.Equivalences) map[string]bool {
+	planned := make(map[string]bool, len(courses))
+	for _, c := range courses {
+		equivalences.Mark(planned, c.Code)
+	}
+	return planned
+}
+
+// evaluatePlan walks the semesters in order, starting from the prior credits,
+// and returns the first unmet requirement of every planned course keyed by
+// course ID. Courses with unmet requirements don't count towards later
+// courses, so breakage is transitive
+func evaluatePlan(priorCredits []PriorCredit, semesters []Semester, courses map[primitive.ObjectID]*course.CourseDB, equivalences course.Equivalences) map[primitive.ObjectID]UnmetRequirement {
+	unmet := make(map[primitive.ObjectID]UnmetRequirement)
+	s := newPlanState(priorCredits, courses, equivalences)
+
+	for _, semester := range semesters {
+		s.enter(semester)
+
+		satisfied := []primitive.ObjectID{}
+		for _, courseID := range semester.Courses {
+			c, ok := courses[courseID]
+			if !ok {
+				continue
+			}
+			if req, ok := s.unmetRequirement(c); ok {
+				unmet[courseID] = req
+				continue
+			}
+			satisfied = append(satisfied, courseID)
+		}
+
+		for _, courseID := range satisfied {
+			s.take(courses[courseID].Code, semester.Grades[courseID.Hex()])
+		}
+	}
+
+	return unmet
+}
@@ -0,0 +1,169 @@
+package degree
+
+import (
+	"testing"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testCourse returns a course with a new ID and the prerequisite expression,
+// an empty expression means no prerequisites
+func testCourse(code string, prereqs string) *course.CourseDB {
+	c := &course.CourseDB{
+		ID:   primitive.NewObjectID(),
+		Code: code,
+	}
+	if prereqs != "" {
+		expr, err := course.ParsePrerequisiteExpr(prereqs)
+		if err != nil {
+			panic(err)
+		}
+		c.PrerequisiteTree = expr
+		c.Prerequisites = expr.Flatten()
+	}
+	return c
+}
+
+// testCatalog keys the courses by ID and by code
+func testCatalog(courses ...*course.CourseDB) (map[primitive.ObjectID]*course.CourseDB, map[string]*course.CourseDB) {
+	byID := make(map[primitive.ObjectID]*course.CourseDB, len(courses))
+	byCode := make(map[string]*course.CourseDB, len(courses))
+	for _, c := range courses {
+		byID[c.ID] = c
+		byCode[c.Code] = c
+	}
+	return byID, byCode
+}
+
+// testSemester returns a semester planning the courses
+func testSemester(courses ...*course.CourseDB) Semester {
+	semester := Semester{Courses: []primitive.ObjectID{}}
+	for _, c := range courses {
+		semester.Courses = append(semester.Courses, c.ID)
+	}
+	return semester
+}
+
+func TestEvaluatePlan__Grades(t *testing.T) {
+	cs1 := testCourse("CSCI-1100", "")
+	physics := testCourse("PHYS-1100", "")
+
+	// Flat prerequisite groups without an expression tree, i.e older catalog data
+	flat := testCourse("CSCI-1200", "")
+	flat.Prerequisites = [][]string{{"CSCI-1100"}}
+
+	tests := []struct {
+		name    string
+		prereqs string
+		grade   string
+		unmet   bool
+	}{
+		{"passing grade", "CSCI-1100", "B", false},
+		{"grade not recorded yet", "CSCI-1100", "", false},
+		{"failing grade", "CSCI-1100", "F", true},
+		{"meets minimum grade", "CSCI-1100 [min C]", "C", false},
+		{"above minimum grade", "CSCI-1100 [min C]", "A-", false},
+		{"below minimum grade", "CSCI-1100 [min C]", "C-", true},
+		{"failing grade with or branch", "CSCI-1100 or PHYS-1100", "F", false},
+	}
+
+	for _, test := range tests {
+		next := testCourse("CSCI-1200", test.prereqs)
+		courses, _ := testCatalog(cs1, physics, next)
+		first := testSemester(cs1, physics)
+		first.Grades = map[string]string{cs1.ID.Hex(): test.grade}
+		if test.grade == "" {
+			first.Grades = nil
+		}
+
+		unmet := evaluatePlan(nil, []Semester{first, testSemester(next)}, courses, course.Equivalences{})
+		if _, ok := unmet[next.ID]; ok != test.unmet {
+			t.Errorf("%s: expected unmet %v, got %v", test.name, test.unmet, unmet)
+		}
+	}
+
+	// The flat fallback respects grades too
+	for grade, expected := range map[string]bool{"A": false, "D": false, "F": true} {
+		courses, _ := testCatalog(cs1, flat)
+		first := testSemester(cs1)
+		first.Grades = map[string]string{cs1.ID.Hex(): grade}
+
+		unmet := evaluatePlan(nil, []Semester{first, testSemester(flat)}, courses, course.Equivalences{})
+		if _, ok := unmet[flat.ID]; ok != expected {
+			t.Errorf("flat prerequisites with grade %s: expected unmet %v, got %v", grade, expected, unmet)
+		}
+	}
+
+	// A retake keeps the best grade, an ungraded retake is assumed to pass
+	itws := testCourse("ITWS-1100", "")
+	equivalences := course.Equivalences{
+		"CSCI-1100": {"CSCI-1100", "ITWS-1100"},
+		"ITWS-1100": {"CSCI-1100", "ITWS-1100"},
+	}
+
+	retakes := []struct {
+		name    string
+		prereqs string
+		retake  *course.CourseDB
+		first   string
+		second  string
+		unmet   bool
+	}{
+		{"F then retaken ungraded", "CSCI-1100", cs1, "F", "", false},
+		{"F then retaken with a passing grade", "CSCI-1100", cs1, "F", "C", false},
+		{"F then F", "CSCI-1100", cs1, "F", "F", true},
+		{"B then D", "CSCI-1100 [min C]", cs1, "B", "D", false},
+		{"D then B", "CSCI-1100 [min C]", cs1, "D", "B", false},
+		{"D then D", "CSCI-1100 [min C]", cs1, "D", "D", true},
+		{"F then cross-listed retake ungraded", "CSCI-1100", itws, "F", "", false},
+		{"B then cross-listed retake with D", "CSCI-1100 [min C]", itws, "B", "D", false},
+	}
+
+	for _, test := range retakes {
+		next := testCourse("CSCI-1200", test.prereqs)
+		courses, _ := testCatalog(cs1, itws, next)
+		first := testSemester(cs1)
+		first.Grades = map[string]string{cs1.ID.Hex(): test.first}
+		second := testSemester(test.retake)
+		if test.second != "" {
+			second.Grades = map[string]string{test.retake.ID.Hex(): test.second}
+		}
+
+		unmet := evaluatePlan(nil, []Semester{first, second, testSemester(next)}, courses, equivalences)
+		if _, ok := unmet[next.ID]; ok != test.unmet {
+			t.Errorf("%s: expected unmet %v, got %v", test.name, test.unmet, unmet)
+		}
+	}
+}
+
+func TestEvaluatePlan__Concurrent(t *testing.T) {
+	physics := testCourse("PHYS-1100", "")
+	lab := testCourse("PHYS-1150", "PHYS-1100 [concurrent]")
+	calc := testCourse("MATH-1020", "PHYS-1100")
+	courses, _ := testCatalog(physics, lab, calc)
+
+	tests := []struct {
+		name      string
+		semesters []Semester
+		unmet     []*course.CourseDB
+	}{
+		{"concurrent in the same semester", []Semester{testSemester(physics, lab)}, nil},
+		{"concurrent taken before", []Semester{testSemester(physics), testSemester(lab)}, nil},
+		{"concurrent taken after", []Semester{testSemester(lab), testSemester(physics)}, []*course.CourseDB{lab}},
+		{"not concurrent in the same semester", []Semester{testSemester(physics, calc)}, []*course.CourseDB{calc}},
+	}
+
+	for _, test := range tests {
+		unmet := evaluatePlan(nil, test.semesters, courses, course.Equivalences{})
+		if len(unmet) != len(test.unmet) {
+			t.Errorf("%s: expected %v unmet, got %v", test.name, len(test.unmet), unmet)
+			continue
+		}
+		for _, c := range test.unmet {
+			if _, ok := unmet[c.ID]; !ok {
+				t.Errorf("%s: expected %v unmet, got %v", test.name, c.Code, unmet)
+			}
+		}
+	}
+}
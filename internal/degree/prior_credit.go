@@ -0,0 +1,128 @@
+package degree
+
+import (
+	"errors"
+
+	"github.com/huynchu/degree-planner-api/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrPriorCreditNotFound      = errors.New("prior credit not found")
+	ErrInvalidPriorCreditSource = errors.New("invalid prior credit source")
+	ErrInvalidPriorCredit       = errors.New("prior credit must have a course or a description and non negative credits")
+)
+
+func (ds *DegreeService) FindPriorCredits(degreeID string) ([]PriorCredit, error) {
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if degree.PriorCredits == nil {
+		return []PriorCredit{}, nil
+	}
+	return degree.PriorCredits, nil
+}
+
+func (ds *DegreeService) AddPriorCredit(degreeID string, source string, courseID string, description string, credits int) (string, error) {
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return "", err
+	}
+
+	priorCredit, err := ds.newPriorCredit(degree.CatalogYear, primitive.NewObjectID(), source, courseID, description, credits)
+	if err != nil {
+		return "", err
+	}
+
+	degree.PriorCredits = append(degree.PriorCredits, *priorCredit)
+
+	err = ds.degreeStorage.UpdatePriorCredits(degreeID, degree.PriorCredits)
+	if err != nil {
+		return "", err
+	}
+
+	return priorCredit.ID.Hex(), nil
+}
+
+func (ds *DegreeService) UpdatePriorCredit(degreeID string, priorCreditID string, source string, courseID string, description string, credits int) error {
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return err
+	}
+
+	index := findPriorCredit(degree.PriorCredits, priorCreditID)
+	if index == -1 {
+		return ErrPriorCreditNotFound
+	}
+
+	priorCredit, err := ds.newPriorCredit(degree.CatalogYear, degree.PriorCredits[index].ID, source, courseID, description, credits)
+	if err != nil {
+		return err
+	}
+
+	degree.PriorCredits[index] = *priorCredit
+
+	return ds.degreeStorage.UpdatePriorCredits(degreeID, degree.PriorCredits)
+}
+
+func (ds *DegreeService) RemovePriorCredit(degreeID string, priorCreditID string) error {
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return err
+	}
+
+	index := findPriorCredit(degree.PriorCredits, priorCreditID)
+	if index == -1 {
+		return ErrPriorCreditNotFound
+	}
+
+	degree.PriorCredits = utils.Remove(degree.PriorCredits, index)
+
+	return ds.degreeStorage.UpdatePriorCredits(degreeID, degree.PriorCredits)
+}
+
+// newPriorCredit validates the prior credit fields and resolves the equivalent
+// course in the catalog year of the degree
+func (ds *DegreeService) newPriorCredit(catalogYear string, id primitive.ObjectID, source string, courseID string, description string, credits int) (*PriorCredit, error) {
+	switch source {
+	case PriorCreditAP, PriorCreditTransfer, PriorCreditPlacement:
+	default:
+		return nil, ErrInvalidPriorCreditSource
+	}
+
+	if (courseID == "" && description == "") || credits < 0 {
+		return nil, ErrInvalidPriorCredit
+	}
+
+	priorCredit := PriorCredit{
+		ID:          id,
+		Source:      source,
+		Description: description,
+		Credits:     credits,
+	}
+
+	// Check if equivalent course exists
+	if courseID != "" {
+		course, err := ds.courseService.FindCourseByID(courseID)
+		if err != nil {
+			return nil, err
+		}
+		if course.CatalogYear != catalogYear {
+			return nil, ErrCourseNotInCatalogYear
+		}
+		priorCredit.CourseID = &course.ID
+	}
+
+	return &priorCredit, nil
+}
+
+func findPriorCredit(priorCredits []PriorCredit, priorCreditID string) int {
+	for i, priorCredit := range priorCredits {
+		if priorCredit.ID.Hex() == priorCreditID {
+			return i
+		}
+	}
+	return -1
+}
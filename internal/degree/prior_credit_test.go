@@ -0,0 +1,88 @@
+package degree
+
+import (
+	"testing"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewPriorCredit(t *testing.T) {
+	ds := &DegreeService{}
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name        string
+		source      string
+		description string
+		credits     int
+		err         error
+	}{
+		{"ap description", PriorCreditAP, "AP Calculus BC", 8, nil},
+		{"transfer without credits", PriorCreditTransfer, "Community college elective", 0, nil},
+		{"unknown source", "exam", "AP Calculus BC", 4, ErrInvalidPriorCreditSource},
+		{"no course or description", PriorCreditPlacement, "", 4, ErrInvalidPriorCredit},
+		{"negative credits", PriorCreditAP, "AP Physics", -4, ErrInvalidPriorCredit},
+	}
+
+	for _, test := range tests {
+		priorCredit, err := ds.newPriorCredit("2024-2025", id, test.source, "", test.description, test.credits)
+		if err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+			continue
+		}
+		if err == nil && (priorCredit.ID != id || priorCredit.CourseID != nil || priorCredit.Credits != test.credits) {
+			t.Errorf("%s: unexpected prior credit %+v", test.name, priorCredit)
+		}
+	}
+}
+
+func TestEvaluatePlan__PriorCredits(t *testing.T) {
+	calc1 := testCourse("MATH-1010", "")
+	calc2 := testCourse("MATH-1020", "MATH-1010")
+	courses, _ := testCatalog(calc1, calc2)
+	semesters := []Semester{testSemester(calc2)}
+
+	tests := []struct {
+		name         string
+		priorCredits []PriorCredit
+		unmet        bool
+	}{
+		{"no prior credit", nil, true},
+		{"equivalent course", []PriorCredit{{Source: PriorCreditAP, CourseID: &calc1.ID, Credits: 4}}, false},
+		{"description only", []PriorCredit{{Source: PriorCreditAP, Description: "AP Calculus AB", Credits: 4}}, true},
+		{"credit for another course", []PriorCredit{{Source: PriorCreditTransfer, CourseID: &calc2.ID, Credits: 4}}, true},
+	}
+
+	for _, test := range tests {
+		unmet := evaluatePlan(test.priorCredits, semesters, courses, course.Equivalences{})
+		if _, ok := unmet[calc2.ID]; ok != test.unmet {
+			t.Errorf("%s: expected unmet %v, got %v", test.name, test.unmet, unmet)
+		}
+	}
+}
+
+func TestFindPriorCredit(t *testing.T) {
+	priorCredits := []PriorCredit{
+		{ID: primitive.NewObjectID(), Source: PriorCreditAP},
+		{ID: primitive.NewObjectID(), Source: PriorCreditTransfer},
+	}
+
+	if i := findPriorCredit(priorCredits, priorCredits[1].ID.Hex()); i != 1 {
+		t.Errorf("Expected index 1, got %v", i)
+	}
+	if i := findPriorCredit(priorCredits, primitive.NewObjectID().Hex()); i != -1 {
+		t.Errorf("Expected index -1, got %v", i)
+	}
+}
+
+func TestPriorCredits(t *testing.T) {
+	credits := []PriorCredit{
+		{Source: PriorCreditAP, Description: "AP Calculus BC", Credits: 8},
+		{Source: PriorCreditTransfer, Description: "Writing", Credits: 4},
+		{Source: PriorCreditPlacement, Description: "Language placement", Credits: 0},
+	}
+	if total := priorCredits(credits); total != 12 {
+		t.Errorf("Expected 12 credits, got %v", total)
+	}
+}
@@ -6,13 +6,23 @@ func AddDegreeRoutes(r chi.Router, controller *DegreeController) {
 	// Degree routes
 	r.Post("/api/degrees", controller.CreateDegree)
 	r.Get("/api/degrees/{degreeID}", controller.FindDegreeByID)
+	r.Get("/api/degrees/{degreeID}/audit", controller.AuditDegree)
+	r.Post("/api/degrees/{degreeID}/catalog-year", controller.MigrateCatalogYear)
 
 	// Degree Semesters routes
 	r.Post("/api/degrees/{degreeID}/semesters", controller.AddSemester)
 	r.Put("/api/degrees/{degreeID}/semesters/{index}/move", controller.MoveSemester)
 	r.Delete("/api/degrees/{degreeID}/semesters/{index}", controller.DeleteSemester)
+	r.Get("/api/degrees/{degreeID}/semesters/{index}/eligible", controller.EligibleCourses)
+
+	// Degree Prior Credits routes
+	r.Get("/api/degrees/{degreeID}/prior-credits", controller.FindPriorCredits)
+	r.Post("/api/degrees/{degreeID}/prior-credits", controller.AddPriorCredit)
+	r.Put("/api/degrees/{degreeID}/prior-credits/{priorCreditID}", controller.UpdatePriorCredit)
+	r.Delete("/api/degrees/{degreeID}/prior-credits/{priorCreditID}", controller.RemovePriorCredit)
 
 	// Degree Semester Courses routes
 	r.Post("/api/degrees/{degreeID}/semesters/{index}/courses", controller.AddCourseToSemester)
 	r.Delete("/api/degrees/{degreeID}/semesters/{index}/courses/{courseID}", controller.RemoveCourseFromSemester)
+	r.Put("/api/degrees/{degreeID}/semesters/{index}/courses/{courseID}/grade", controller.RecordGrade)
 }
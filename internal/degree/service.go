@@ -12,6 +12,10 @@ var (
 	ErrSemesterIndexOutOfBounds      = errors.New("semester index out of bounds")
 	ErrCourseAlreadyExistsInSemester = errors.New("course already exists in semester")
 	ErrCourseDoesNotExistInSemester  = errors.New("course does not exist in semester")
+	ErrInvalidSemesterTerm           = errors.New("invalid semester term")
+	ErrInvalidGrade                  = errors.New("invalid grade")
+	ErrInvalidCatalogYear            = errors.New("invalid catalog year")
+	ErrCourseNotInCatalogYear        = errors.New("course is not in the degree catalog year")
 )
 
 type DegreeService struct {
@@ -27,8 +31,19 @@ func NewDegreeService(ds *DegreeStorage, cs *course.CourseService) *DegreeServic
 	}
 }
 
-func (ds *DegreeService) CreateDegree(name string) (string, error) {
-	return ds.degreeStorage.CreateDegree(name)
+// CreateDegree creates a degree pinned to the catalog year, defaulting to the
+// latest catalog year
+func (ds *DegreeService) CreateDegree(name string, catalogYear string) (string, error) {
+	if catalogYear == "" {
+		latest, err := ds.courseService.LatestCatalogYear()
+		if err != nil {
+			return "", err
+		}
+		catalogYear = latest
+	} else if !course.IsValidCatalogYear(catalogYear) {
+		return "", ErrInvalidCatalogYear
+	}
+	return ds.degreeStorage.CreateDegree(name, catalogYear)
 }
 
 func (ds *DegreeService) FindDegreeByID(id string) (*DegreeAggregated, error) {
@@ -38,15 +53,19 @@ func (ds *DegreeService) FindDegreeByID(id string) (*DegreeAggregated, error) {
 	}
 
 	degreeAggregated := DegreeAggregated{
-		ID:        degree.ID,
-		Name:      degree.Name,
-		Semesters: []SemesterAggregated{},
-		Owner:     degree.Owner,
+		ID:           degree.ID,
+		Name:         degree.Name,
+		CatalogYear:  degree.CatalogYear,
+		Semesters:    []SemesterAggregated{},
+		PriorCredits: []PriorCreditAggregated{},
+		Owner:        degree.Owner,
 	}
 
 	for _, semester := range degree.Semesters {
 		semesterAggregated := SemesterAggregated{
 			Name:    semester.Name,
+			Term:    semester.Term,
+			Year:    semester.Year,
 			Courses: []course.CourseDB{},
 		}
 		for _, courseID := range semester.Courses {
@@ -59,10 +78,27 @@ func (ds *DegreeService) FindDegreeByID(id string) (*DegreeAggregated, error) {
 		degreeAggregated.Semesters = append(degreeAggregated.Semesters, semesterAggregated)
 	}
 
+	for _, priorCredit := range degree.PriorCredits {
+		priorCreditAggregated := PriorCreditAggregated{
+			ID:          priorCredit.ID,
+			Source:      priorCredit.Source,
+			Description: priorCredit.Description,
+			Credits:     priorCredit.Credits,
+		}
+		if priorCredit.CourseID != nil {
+			course, err := ds.courseService.FindCourseByID(priorCredit.CourseID.Hex())
+			if err != nil {
+				return nil, err
+			}
+			priorCreditAggregated.Course = course
+		}
+		degreeAggregated.PriorCredits = append(degreeAggregated.PriorCredits, priorCreditAggregated)
+	}
+
 	return &degreeAggregated, nil
 }
 
-func (ds *DegreeService) AddSemester(degreeID string, semesterName string, semesterIndex int) error {
+func (ds *DegreeService) AddSemester(degreeID string, semesterName string, term string, year int, semesterIndex int) error {
 	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
 	if err != nil {
 		return err
@@ -72,8 +108,14 @@ func (ds *DegreeService) AddSemester(degreeID string, semesterName string, semes
 		return ErrSemesterIndexOutOfBounds
 	}
 
+	if term != "" && !course.IsValidTerm(term) {
+		return ErrInvalidSemesterTerm
+	}
+
 	semester := Semester{
 		Name:    semesterName,
+		Term:    term,
+		Year:    year,
 		Courses: []primitive.ObjectID{},
 	}
 
@@ -109,17 +151,15 @@ func (ds *DegreeService) MoveSemester(degreeID string, semesterIndex int, newInd
 		return err
 	}
 
-	if semesterIndex < 0 || semesterIndex >= len(degree.Semesters) ||
-		newIndex < 0 || newIndex >= len(degree.Semesters) {
-		return ErrSemesterIndexOutOfBounds
+	degree.Semesters, err = moveSemester(degree.Semesters, semesterIndex, newIndex)
+	if err != nil {
+		return err
 	}
 
 	if semesterIndex == newIndex {
 		return nil
 	}
 
-	degree.Semesters = utils.Move(degree.Semesters, semesterIndex, newIndex)
-
 	err = ds.degreeStorage.UpdateSemesters(degreeID, degree.Semesters)
 	return err
 }
@@ -141,6 +181,9 @@ func (ds *DegreeService) AddCourseToSemester(degreeID string, semesterIndex int,
 	if err != nil {
 		return err
 	}
+	if course.CatalogYear != degree.CatalogYear {
+		return ErrCourseNotInCatalogYear
+	}
 
 	// Check if course already exists in semester
 	for _, courseID := range degree.Semesters[semesterIndex].Courses {
@@ -164,24 +207,55 @@ func (ds *DegreeService) RemoveCourseFromSemester(degreeID string, semesterIndex
 		return err
 	}
 
+	// Remove course from semester
+	degree.Semesters, err = removeCourse(degree.Semesters, semesterIndex, courseID)
+	if err != nil {
+		return err
+	}
+
+	// Update degree
+	err = ds.degreeStorage.UpdateSemesters(degreeID, degree.Semesters)
+	return err
+}
+
+func (ds *DegreeService) RecordGrade(degreeID string, semesterIndex int, courseID string, grade string) error {
+	// Check if degree exists
+	degree, err := ds.degreeStorage.FindDegreeByID(degreeID)
+	if err != nil {
+		return err
+	}
+
 	// Check if semester exists
 	if semesterIndex < 0 || semesterIndex >= len(degree.Semesters) {
 		return ErrSemesterIndexOutOfBounds
 	}
 
-	// Remove course from semester
+	if grade != "" && !course.IsValidGrade(grade) {
+		return ErrInvalidGrade
+	}
+
+	// Check if course exists in semester
 	semester := &degree.Semesters[semesterIndex]
-	courseIndex := -1
-	for i, course := range semester.Courses {
-		if course.Hex() == courseID {
-			courseIndex = i
+	found := false
+	for _, id := range semester.Courses {
+		if id.Hex() == courseID {
+			found = true
 			break
 		}
 	}
-	if courseIndex == -1 {
+	if !found {
 		return ErrCourseDoesNotExistInSemester
 	}
-	semester.Courses = utils.Remove(semester.Courses, courseIndex)
+
+	// Record grade, an empty grade clears it
+	if semester.Grades == nil {
+		semester.Grades = make(map[string]string)
+	}
+	if grade == "" {
+		delete(semester.Grades, courseID)
+	} else {
+		semester.Grades[courseID] = grade
+	}
 
 	// Update degree
 	err = ds.degreeStorage.UpdateSemesters(degreeID, degree.Semesters)
@@ -9,28 +9,63 @@ import (
 )
 
 type DegreeAggregated struct {
-	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
-	Name      string               `bson:"name" json:"name"`
-	Semesters []SemesterAggregated `bson:"semesters" json:"semesters"`
-	Owner     primitive.ObjectID   `bson:"owner,omitempty" json:"owner,omitempty"`
+	ID           primitive.ObjectID      `bson:"_id,omitempty" json:"id,omitempty"`
+	Name         string                  `bson:"name" json:"name"`
+	CatalogYear  string                  `bson:"catalogYear,omitempty" json:"catalogYear,omitempty"`
+	Semesters    []SemesterAggregated    `bson:"semesters" json:"semesters"`
+	PriorCredits []PriorCreditAggregated `bson:"priorCredits" json:"priorCredits"`
+	Owner        primitive.ObjectID      `bson:"owner,omitempty" json:"owner,omitempty"`
 }
 
 type SemesterAggregated struct {
 	Name    string            `bson:"name" json:"name"`
+	Term    string            `bson:"term,omitempty" json:"term,omitempty"`
+	Year    int               `bson:"year,omitempty" json:"year,omitempty"`
 	Courses []course.CourseDB `bson:"courses" json:"courses"`
 }
 
-// How Course looks in MongoDB
+type PriorCreditAggregated struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Source      string             `bson:"source" json:"source"`
+	Course      *course.CourseDB   `bson:"course,omitempty" json:"course,omitempty"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Credits     int                `bson:"credits" json:"credits"`
+}
+
+// How Course looks in MongoDB. Courses of the degree resolve against the
+// catalog year the degree was started under
 type DegreeDB struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Name      string             `bson:"name" json:"name"`
-	Semesters []Semester         `bson:"semesters" json:"semesters"`
-	Owner     primitive.ObjectID `bson:"owner,omitempty" json:"owner,omitempty"`
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name         string             `bson:"name" json:"name"`
+	CatalogYear  string             `bson:"catalogYear,omitempty" json:"catalogYear,omitempty"`
+	Semesters    []Semester         `bson:"semesters" json:"semesters"`
+	PriorCredits []PriorCredit      `bson:"priorCredits" json:"priorCredits"`
+	Owner        primitive.ObjectID `bson:"owner,omitempty" json:"owner,omitempty"`
 }
 
 type Semester struct {
 	Name    string               `bson:"name" json:"name"`
+	Term    string               `bson:"term,omitempty" json:"term,omitempty"`
+	Year    int                  `bson:"year,omitempty" json:"year,omitempty"`
 	Courses []primitive.ObjectID `bson:"courses" json:"courses"`
+	// Recorded letter grades keyed by course ID
+	Grades map[string]string `bson:"grades,omitempty" json:"grades,omitempty"`
+}
+
+const (
+	PriorCreditAP        = "ap"
+	PriorCreditTransfer  = "transfer"
+	PriorCreditPlacement = "placement"
+)
+
+// Credit earned before the first semester, i.e AP or transfer credit. The credit
+// either maps to an equivalent catalog course or is described in free text
+type PriorCredit struct {
+	ID          primitive.ObjectID  `bson:"_id" json:"id"`
+	Source      string              `bson:"source" json:"source"`
+	CourseID    *primitive.ObjectID `bson:"courseID,omitempty" json:"courseID,omitempty"`
+	Description string              `bson:"description,omitempty" json:"description,omitempty"`
+	Credits     int                 `bson:"credits" json:"credits"`
 }
 
 type DegreeStorage struct {
@@ -44,12 +79,14 @@ func NewDegreeStorage(db *mongo.Database) *DegreeStorage {
 	}
 }
 
-func (d *DegreeStorage) CreateDegree(name string) (string, error) {
+func (d *DegreeStorage) CreateDegree(name string, catalogYear string) (string, error) {
 	collection := d.db.Collection("degree")
 
 	newDegree := DegreeDB{
-		Name:      name,
-		Semesters: []Semester{},
+		Name:         name,
+		CatalogYear:  catalogYear,
+		Semesters:    []Semester{},
+		PriorCredits: []PriorCredit{},
 	}
 
 	// Insert the course
@@ -138,3 +175,59 @@ func (d *DegreeStorage) UpdateSemesters(degreeID string, semesters []Semester) e
 
 	return nil
 }
+
+func (d *DegreeStorage) UpdatePriorCredits(degreeID string, priorCredits []PriorCredit) error {
+	collection := d.db.Collection("degree")
+
+	objId, err := primitive.ObjectIDFromHex(degreeID)
+	if err != nil {
+		return err
+	}
+
+	// Update the prior credits
+	filter := primitive.M{"_id": objId}
+	_, err = collection.UpdateOne(
+		context.Background(),
+		filter,
+		primitive.M{
+			"$set": primitive.M{
+				"priorCredits": priorCredits,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateCatalogYear pins the degree to the catalog year along with the semesters
+// and prior credits remapped to the courses of that year
+func (d *DegreeStorage) UpdateCatalogYear(degreeID string, catalogYear string, semesters []Semester, priorCredits []PriorCredit) error {
+	collection := d.db.Collection("degree")
+
+	objId, err := primitive.ObjectIDFromHex(degreeID)
+	if err != nil {
+		return err
+	}
+
+	// Update the catalog year
+	filter := primitive.M{"_id": objId}
+	_, err = collection.UpdateOne(
+		context.Background(),
+		filter,
+		primitive.M{
+			"$set": primitive.M{
+				"catalogYear":  catalogYear,
+				"semesters":    semesters,
+				"priorCredits": priorCredits,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type HealthController struct {
+	healthService *HealthService
+}
+
+func NewHealthController(hsrv *HealthService) *HealthController {
+	return &HealthController{
+		healthService: hsrv,
+	}
+}
+
+func (hc *HealthController) CatalogSyncHealth(w http.ResponseWriter, r *http.Request) {
+	// fetch catalog sync health
+	health, err := hc.healthService.CatalogSyncHealth()
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "database fetch error: fetch sync runs", http.StatusInternalServerError)
+		return
+	}
+
+	// a failing sync is reported as unavailable for uptime checks
+	status := http.StatusOK
+	if health.Status == StatusFailing {
+		status = http.StatusServiceUnavailable
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(health)
+}
@@ -0,0 +1,7 @@
+package health
+
+import "github.com/go-chi/chi/v5"
+
+func AddHealthRoutes(r chi.Router, controller *HealthController) {
+	r.Get("/health/catalog-sync", controller.CatalogSyncHealth)
+}
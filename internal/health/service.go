@@ -0,0 +1,64 @@
+package health
+
+import (
+	"time"
+
+	"github.com/huynchu/degree-planner-api/internal/workers"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusDisabled = "disabled"
+)
+
+// The health of the catalog sync, the last run is the latest run of any
+// replica or the data worker command
+type CatalogSyncHealth struct {
+	Status           string             `json:"status"`
+	SchedulerEnabled bool               `json:"schedulerEnabled"`
+	Schedule         string             `json:"schedule,omitempty"`
+	NextRun          *time.Time         `json:"nextRun,omitempty"`
+	LastRun          *workers.SyncRunDB `json:"lastRun,omitempty"`
+}
+
+type HealthService struct {
+	syncRunStorage *workers.SyncRunStorage
+	// nil if the scheduler is disabled
+	scheduler *workers.Scheduler
+}
+
+func NewHealthService(srs *workers.SyncRunStorage, scheduler *workers.Scheduler) *HealthService {
+	return &HealthService{
+		syncRunStorage: srs,
+		scheduler:      scheduler,
+	}
+}
+
+func (hs *HealthService) CatalogSyncHealth() (*CatalogSyncHealth, error) {
+	runs, err := hs.syncRunStorage.FindSyncRuns(1)
+	if err != nil {
+		return nil, err
+	}
+
+	health := CatalogSyncHealth{
+		Status: StatusDisabled,
+	}
+	if len(runs) > 0 {
+		health.LastRun = &runs[0]
+	}
+	if hs.scheduler != nil {
+		status := hs.scheduler.Status()
+		health.SchedulerEnabled = true
+		health.Schedule = status.Schedule
+		if !status.NextRun.IsZero() {
+			health.NextRun = &status.NextRun
+		}
+		health.Status = StatusOK
+	}
+	if health.LastRun != nil && health.LastRun.Status == workers.SyncRunFailed {
+		health.Status = StatusFailing
+	}
+
+	return &health, nil
+}
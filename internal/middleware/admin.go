@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/huynchu/degree-planner-api/internal/user"
+)
+
+// RequireAdmin only lets admin users through, it must be added after the auth
+// middleware so the user is in the request context
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usr, ok := user.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized: missing user", http.StatusUnauthorized)
+			return
+		}
+		if !usr.Admin {
+			http.Error(w, "Forbidden: admin only", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
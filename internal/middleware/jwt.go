@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 
@@ -10,12 +9,10 @@ import (
 	"github.com/huynchu/degree-planner-api/internal/utils"
 )
 
-type authCtxKey struct{}
-
-// Auth middleware validates an incoming request jwt token and adds the Account._id of that user to
-// the request context. Add this middleware to a route and extract the accountID as follow:
+// Auth middleware validates an incoming request jwt token and adds the User of that token to
+// the request context. Add this middleware to a route and extract the user as follow:
 //
-//	authedAccountID := ctx.Value(authCtxKey{}).(primitive.ObjectID)
+//	authedUser, ok := user.FromContext(ctx)
 
 func NewAuthMiddleWare(userService *user.UserService) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -61,7 +58,7 @@ func NewAuthMiddleWare(userService *user.UserService) func(next http.Handler) ht
 			fmt.Println(usr)
 
 			// Add the User to the request context
-			ctx := context.WithValue(r.Context(), authCtxKey{}, usr)
+			ctx := user.NewContext(r.Context(), usr)
 			r = r.WithContext(ctx)
 
 			next.ServeHTTP(w, r)
@@ -0,0 +1,16 @@
+package user
+
+import "context"
+
+type userCtxKey struct{}
+
+// NewContext returns a copy of ctx carrying the authenticated user
+func NewContext(ctx context.Context, usr *UserDB) context.Context {
+	return context.WithValue(ctx, userCtxKey{}, usr)
+}
+
+// FromContext returns the authenticated user added by the auth middleware
+func FromContext(ctx context.Context) (*UserDB, bool) {
+	usr, ok := ctx.Value(userCtxKey{}).(*UserDB)
+	return usr, ok
+}
@@ -1,11 +1,64 @@
 package user
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
 type UserController struct {
 	userService *UserService
 }
 
-func NewCourseController(usrv *UserService) *UserController {
+func NewUserController(usrv *UserService) *UserController {
 	return &UserController{
 		userService: usrv,
 	}
 }
+
+func (uc *UserController) FindMe(w http.ResponseWriter, r *http.Request) {
+	usr, ok := FromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized: missing user", http.StatusUnauthorized)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(usr)
+}
+
+type UpdateMeRequest struct {
+	Major string `json:"major"`
+}
+
+func (uc *UserController) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	usr, ok := FromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized: missing user", http.StatusUnauthorized)
+		return
+	}
+
+	// decode json body
+	var updateMeReq UpdateMeRequest
+	err := json.NewDecoder(r.Body).Decode(&updateMeReq)
+	if err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	// update user
+	err = uc.userService.UpdateMajor(usr, strings.ToUpper(updateMeReq.Major))
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "database update error: update user", http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with json
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode("updated user successfully")
+}
@@ -0,0 +1,8 @@
+package user
+
+import "github.com/go-chi/chi/v5"
+
+func AddUserRoutes(r chi.Router, controller *UserController) {
+	r.Get("/api/users/me", controller.FindMe)
+	r.Put("/api/users/me", controller.UpdateMe)
+}
@@ -6,6 +6,7 @@ var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrDatabaseFetchUser  = errors.New("error fetching user from database")
 	ErrDatabaseCreateUser = errors.New("error creating user in database")
+	ErrDatabaseUpdateUser = errors.New("error updating user in database")
 )
 
 type UserService struct {
@@ -23,3 +24,7 @@ func (s *UserService) FindUserByEmail(email string) (*UserDB, error) {
 func (s *UserService) CreateNewUser(email string) (string, error) {
 	return s.userStorage.CreateNewUser(email)
 }
+
+func (s *UserService) UpdateMajor(usr *UserDB, major string) error {
+	return s.userStorage.UpdateMajor(usr.ID, major)
+}
@@ -9,9 +9,13 @@ import (
 )
 
 type UserDB struct {
-	ID      primitive.ObjectID   `bson:"_id,omitempty"`
-	Email   string               `bson:"email"`
-	Degrees []primitive.ObjectID `bson:"degrees"`
+	ID      primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	Email   string               `bson:"email" json:"email"`
+	Degrees []primitive.ObjectID `bson:"degrees" json:"degrees"`
+	// Subject of the user's major, i.e CSCI
+	Major string `bson:"major,omitempty" json:"major,omitempty"`
+	// Admins manage the course catalog
+	Admin bool `bson:"admin,omitempty" json:"admin,omitempty"`
 }
 
 type UserStorage struct {
@@ -47,3 +51,13 @@ func (s *UserStorage) CreateNewUser(email string) (string, error) {
 	id := res.InsertedID.(primitive.ObjectID).Hex()
 	return id, nil
 }
+
+func (s *UserStorage) UpdateMajor(id primitive.ObjectID, major string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"major": major}}
+	_, err := s.db.Collection("users").UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return ErrDatabaseUpdateUser
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CatalogFormatQuatalog = "quatalog"
+	CatalogFormatCSV      = "csv"
+	CatalogFormatYAML     = "yaml"
+)
+
+// A catalog normalized from a source format, courses are keyed by code and
+// cross-listings map each course code to the codes it is cross-listed with.
+// Invalid entries are only kept by a lenient load
+type Catalog struct {
+	Courses       map[string]*course.CourseDB
+	CrossListings map[string][]string
+	Invalid       []InvalidEntry
+}
+
+const (
+	InvalidEntryMissingField  = "missing_field"
+	InvalidEntryDuplicateCode = "duplicate_code"
+	InvalidEntryPrerequisites = "unparsable_prerequisites"
+	InvalidEntryClassStanding = "invalid_class_standing"
+)
+
+// An entry of a row based catalog that can't be normalized as is
+type InvalidEntry struct {
+	Line   int
+	Code   string
+	Reason string
+	Err    error
+}
+
+func (e InvalidEntry) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e InvalidEntry) Unwrap() error {
+	return e.Err
+}
+
+// A CatalogAdapter normalizes the datasets of a catalog source in an
+// institution format into courses
+type CatalogAdapter interface {
+	// Datasets the adapter reads from the source
+	Datasets() []string
+	Load(ctx context.Context, source CatalogSource) (*Catalog, error)
+}
+
+// A LenientAdapter can load a catalog whose entries are invalid, the invalid
+// entries are kept in the catalog instead of failing the load, i.e to report
+// every problem of the source data when validating it. A duplicate or an
+// entry without a code or name is skipped, other invalid entries are loaded
+// without the invalid field
+type LenientAdapter interface {
+	LoadLenient(ctx context.Context, source CatalogSource) (*Catalog, error)
+}
+
+// NewCatalogAdapter returns the adapter for the CATALOG_FORMAT, defaulting to
+// the Quatalog json format
+func NewCatalogAdapter(format string) (CatalogAdapter, error) {
+	switch format {
+	case "", CatalogFormatQuatalog:
+		return QuatalogAdapter{}, nil
+	case CatalogFormatCSV:
+		return CSVAdapter{}, nil
+	case CatalogFormatYAML:
+		return YAMLAdapter{}, nil
+	default:
+		return nil, fmt.Errorf("invalid CATALOG_FORMAT %q: must be quatalog, csv or yaml", format)
+	}
+}
+
+// QuatalogAdapter reads the Quatalog catalog and prereq json datasets
+type QuatalogAdapter struct{}
+
+func (QuatalogAdapter) Datasets() []string {
+	return []string{DatasetCatalog, DatasetPrereqs}
+}
+
+func (QuatalogAdapter) Load(ctx context.Context, source CatalogSource) (*Catalog, error) {
+	courseData := make(map[string]*course.CourseDB)
+	crossListings, err := populateCourseData(ctx, source, courseData)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{Courses: courseData, CrossListings: crossListings}, nil
+}
+
+// CSVAdapter reads a course list with a header row, i.e
+//
+//	code,name,credits,prerequisites,corequisites,cross_listings,offered
+//	CSCI-1200,Data Structures,4,CSCI-1100,,,fall;spring
+//
+// Only code and name are required, restrictions use the optional
+// min_class_standing, majors and permission_required columns and the optional
+// description and attributes columns hold the course metadata. Prerequisites
+// are an expression parsed by course.ParsePrerequisiteExpr and list columns
+// are separated by semicolons
+type CSVAdapter struct{}
+
+func (CSVAdapter) Datasets() []string {
+	return []string{DatasetCourseList}
+}
+
+func (a CSVAdapter) Load(ctx context.Context, source CatalogSource) (*Catalog, error) {
+	entries, err := a.entries(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return strictCatalog(buildCatalog(entries))
+}
+
+func (a CSVAdapter) LoadLenient(ctx context.Context, source CatalogSource) (*Catalog, error) {
+	entries, err := a.entries(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return buildCatalog(entries), nil
+}
+
+func (CSVAdapter) entries(ctx context.Context, source CatalogSource) ([]catalogEntry, error) {
+	data, err := source.Fetch(ctx, DatasetCourseList)
+	if err != nil {
+		return nil, fmt.Errorf("fetching course list: %w", err)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading course list header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"code", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("course list is missing the %s column", required)
+		}
+	}
+
+	var entries []catalogEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading course list: %w", err)
+		}
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		var restrictions *course.CourseRestrictions
+		standing, majors, permission := field("min_class_standing"), splitList(field("majors")), field("permission_required")
+		if standing != "" || len(majors) > 0 || permission != "" {
+			restrictions = &course.CourseRestrictions{
+				MinClassStanding:   standing,
+				Majors:             majors,
+				PermissionRequired: strings.EqualFold(permission, "true"),
+			}
+		}
+		line, _ := reader.FieldPos(0)
+		entries = append(entries, catalogEntry{
+			Line:          line,
+			Code:          field("code"),
+			Name:          field("name"),
+			Description:   field("description"),
+			Attributes:    splitList(field("attributes")),
+			Credits:       field("credits"),
+			Prerequisites: field("prerequisites"),
+			Corequisites:  splitList(field("corequisites")),
+			CrossListings: splitList(field("cross_listings")),
+			Offered:       splitList(field("offered")),
+			Restrictions:  restrictions,
+		})
+	}
+
+	return entries, nil
+}
+
+// YAMLAdapter reads a catalog document, i.e
+//
+//	courses:
+//	  - code: CSCI-1200
+//	    name: Data Structures
+//	    credits: 4
+//	    prerequisites: CSCI-1100
+//	    offered: [fall, spring]
+//	    attributes: [Data Intensive I]
+//	    restrictions:
+//	      minClassStanding: sophomore
+type YAMLAdapter struct{}
+
+func (YAMLAdapter) Datasets() []string {
+	return []string{DatasetCatalogYAML}
+}
+
+type yamlCatalog struct {
+	Courses []yamlCourse `yaml:"courses"`
+}
+
+type yamlCourse struct {
+	Code          string            `yaml:"code"`
+	Name          string            `yaml:"name"`
+	Description   string            `yaml:"description"`
+	Attributes    []string          `yaml:"attributes"`
+	Credits       string            `yaml:"credits"` // i.e 4 or 1-4
+	Prerequisites string            `yaml:"prerequisites"`
+	Corequisites  []string          `yaml:"corequisites"`
+	CrossListings []string          `yaml:"crossListings"`
+	Offered       []string          `yaml:"offered"`
+	Restrictions  *yamlRestrictions `yaml:"restrictions"`
+}
+
+type yamlRestrictions struct {
+	MinClassStanding   string   `yaml:"minClassStanding"`
+	Majors             []string `yaml:"majors"`
+	PermissionRequired bool     `yaml:"permissionRequired"`
+}
+
+func (a YAMLAdapter) Load(ctx context.Context, source CatalogSource) (*Catalog, error) {
+	entries, err := a.entries(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return strictCatalog(buildCatalog(entries))
+}
+
+func (a YAMLAdapter) LoadLenient(ctx context.Context, source CatalogSource) (*Catalog, error) {
+	entries, err := a.entries(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return buildCatalog(entries), nil
+}
+
+func (YAMLAdapter) entries(ctx context.Context, source CatalogSource) ([]catalogEntry, error) {
+	data, err := source.Fetch(ctx, DatasetCatalogYAML)
+	if err != nil {
+		return nil, fmt.Errorf("fetching yaml catalog: %w", err)
+	}
+
+	var document yaml.Node
+	err = yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, fmt.Errorf("decoding yaml catalog: %w", err)
+	}
+	var catalog yamlCatalog
+	err = document.Decode(&catalog)
+	if err != nil {
+		return nil, fmt.Errorf("decoding yaml catalog: %w", err)
+	}
+
+	entries := make([]catalogEntry, 0, len(catalog.Courses))
+	for i, c := range catalog.Courses {
+		var restrictions *course.CourseRestrictions
+		if c.Restrictions != nil {
+			restrictions = &course.CourseRestrictions{
+				MinClassStanding:   c.Restrictions.MinClassStanding,
+				Majors:             c.Restrictions.Majors,
+				PermissionRequired: c.Restrictions.PermissionRequired,
+			}
+		}
+		entries = append(entries, catalogEntry{
+			Line:          yamlCourseLine(&document, i),
+			Code:          strings.TrimSpace(c.Code),
+			Name:          strings.TrimSpace(c.Name),
+			Description:   strings.TrimSpace(c.Description),
+			Attributes:    c.Attributes,
+			Credits:       c.Credits,
+			Prerequisites: c.Prerequisites,
+			Corequisites:  c.Corequisites,
+			CrossListings: c.CrossListings,
+			Offered:       c.Offered,
+			Restrictions:  restrictions,
+		})
+	}
+
+	return entries, nil
+}
+
+// yamlCourseLine returns the line of the i-th course in the document
+func yamlCourseLine(document *yaml.Node, i int) int {
+	if len(document.Content) == 0 {
+		return 0
+	}
+	root := document.Content[0]
+	for j := 0; j+1 < len(root.Content); j += 2 {
+		if root.Content[j].Value == "courses" && i < len(root.Content[j+1].Content) {
+			return root.Content[j+1].Content[i].Line
+		}
+	}
+	return 0
+}
+
+// A course read by a row based adapter before it is normalized
+type catalogEntry struct {
+	Line          int
+	Code          string
+	Name          string
+	Description   string
+	Attributes    []string
+	Credits       string
+	Prerequisites string
+	Corequisites  []string
+	CrossListings []string
+	Offered       []string
+	Restrictions  *course.CourseRestrictions
+}
+
+// buildCatalog normalizes the entries into courses, entries that can't be
+// normalized as is are kept as invalid entries with their line
+func buildCatalog(entries []catalogEntry) *Catalog {
+	courseData := make(map[string]*course.CourseDB, len(entries))
+	crossListings := make(map[string][]string)
+	invalid := []InvalidEntry{}
+	for _, e := range entries {
+		if e.Code == "" || e.Name == "" {
+			invalid = append(invalid, InvalidEntry{Line: e.Line, Code: e.Code, Reason: InvalidEntryMissingField,
+				Err: errors.New("course code and name are required")})
+			continue
+		}
+		if _, ok := courseData[e.Code]; ok {
+			invalid = append(invalid, InvalidEntry{Line: e.Line, Code: e.Code, Reason: InvalidEntryDuplicateCode,
+				Err: fmt.Errorf("duplicate course code %s", e.Code)})
+			continue
+		}
+
+		prereqs, err := course.ParsePrerequisiteExpr(e.Prerequisites)
+		if err != nil {
+			invalid = append(invalid, InvalidEntry{Line: e.Line, Code: e.Code, Reason: InvalidEntryPrerequisites,
+				Err: fmt.Errorf("%s prerequisites: %w", e.Code, err)})
+			prereqs = nil
+		}
+
+		// Standings are matched in lowercase, i.e Junior -> junior
+		if e.Restrictions != nil {
+			standing := strings.ToLower(strings.TrimSpace(e.Restrictions.MinClassStanding))
+			if standing != "" && !course.IsValidClassStanding(standing) {
+				invalid = append(invalid, InvalidEntry{Line: e.Line, Code: e.Code, Reason: InvalidEntryClassStanding,
+					Err: fmt.Errorf("%s invalid class standing %q: must be freshman, sophomore, junior or senior", e.Code, e.Restrictions.MinClassStanding)})
+				standing = ""
+			}
+			e.Restrictions.MinClassStanding = standing
+		}
+
+		c := &course.CourseDB{
+			Code:             e.Code,
+			Name:             e.Name,
+			Description:      e.Description,
+			Attributes:       normalizeAttributes(e.Attributes),
+			Prerequisites:    prereqs.Flatten(),
+			PrerequisiteTree: prereqs,
+			Corequisites:     nonNil(e.Corequisites),
+			CrossListings:    nonNil(e.CrossListings),
+			Offered:          course.ParseOfferedTerms(strings.Join(e.Offered, " ")),
+			Credits:          parseCreditsString(e.Credits),
+			Restrictions:     e.Restrictions,
+		}
+		courseData[c.Code] = c
+		crossListings[c.Code] = c.CrossListings
+	}
+
+	return &Catalog{
+		Courses:       courseData,
+		CrossListings: crossListings,
+		Invalid:       invalid,
+	}
+}
+
+// strictCatalog returns the first invalid entry of the catalog as an error
+func strictCatalog(catalog *Catalog) (*Catalog, error) {
+	if len(catalog.Invalid) > 0 {
+		return nil, catalog.Invalid[0]
+	}
+	return catalog, nil
+}
+
+func splitList(s string) []string {
+	res := []string{}
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
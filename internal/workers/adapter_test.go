@@ -0,0 +1,104 @@
+package workers
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Every fixture describes the same catalog in its own format
+func TestCatalogAdapters(t *testing.T) {
+	tests := []struct {
+		format string
+		dir    string
+	}{
+		{CatalogFormatQuatalog, "quatalog"},
+		{CatalogFormatCSV, "csv"},
+		{CatalogFormatYAML, "yaml"},
+	}
+
+	for _, test := range tests {
+		adapter, err := NewCatalogAdapter(test.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		catalog, err := adapter.Load(context.Background(), NewFileSource(filepath.Join("testdata", test.dir)))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.format, err)
+			continue
+		}
+
+		if len(catalog.Courses) != 6 {
+			t.Errorf("%s: expected 6 courses, got %v", test.format, len(catalog.Courses))
+		}
+		algorithms := catalog.Courses["CSCI-2300"]
+		if algorithms == nil {
+			t.Errorf("%s: expected CSCI-2300", test.format)
+			continue
+		}
+		if algorithms.PrerequisiteTree.String() != "CSCI-1200 and MATH-1010 [min C]" {
+			t.Errorf("%s: unexpected CSCI-2300 prerequisites %v", test.format, algorithms.PrerequisiteTree.String())
+		}
+		if len(algorithms.Prerequisites) != 2 {
+			t.Errorf("%s: expected 2 prerequisite groups, got %v", test.format, algorithms.Prerequisites)
+		}
+		if algorithms.Restrictions == nil || algorithms.Restrictions.MinClassStanding != "sophomore" {
+			t.Errorf("%s: expected sophomore standing restriction, got %+v", test.format, algorithms.Restrictions)
+		}
+		if algorithms.Credits != 4 || len(algorithms.Offered) != 2 {
+			t.Errorf("%s: expected 4 credits offered fall and spring, got %v %v", test.format, algorithms.Credits, algorithms.Offered)
+		}
+		if !strings.HasPrefix(algorithms.Description, "Design and analysis") {
+			t.Errorf("%s: expected CSCI-2300 description, got %q", test.format, algorithms.Description)
+		}
+		if strings.Join(algorithms.Attributes, ",") != "Communication Intensive,Data Intensive I" {
+			t.Errorf("%s: expected sorted CSCI-2300 attributes, got %v", test.format, algorithms.Attributes)
+		}
+		groups := crossListingGroups(catalog.CrossListings)
+		if len(groups) != 1 || strings.Join(groups[0], ",") != "CSCI-4100,MATH-4100" {
+			t.Errorf("%s: expected cross-listing group [CSCI-4100 MATH-4100], got %v", test.format, groups)
+		}
+	}
+}
+
+func TestCSVAdapter__Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"missing column", "code,credits\nCSCI-1100,4\n", "missing the name column"},
+		{"missing name", "code,name\nCSCI-1100,\n", "line 2"},
+		{"duplicate code", "code,name\nCSCI-1100,CS I\nCSCI-1100,CS I\n", "line 3: duplicate course code"},
+		{"invalid prerequisites", "code,name,prerequisites\nCSCI-1200,Data Structures,CSCI-1100 and\n", "line 2: CSCI-1200 prerequisites"},
+		{"unknown standing", "code,name,min_class_standing\nCSCI-1100,CS I,Junior\nCSCI-1200,Data Structures,jr\n", `line 3: CSCI-1200 invalid class standing "jr"`},
+	}
+
+	for _, test := range tests {
+		source := NewMemorySource(map[string][]byte{DatasetCourseList: []byte(test.data)})
+		_, err := CSVAdapter{}.Load(context.Background(), source)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestYAMLAdapter__Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"missing name", "courses:\n  - code: CSCI-1100\n    name: CS I\n  - code: CSCI-1200\n", "line 4"},
+		{"unknown standing", "courses:\n  - code: CSCI-1100\n    name: CS I\n    restrictions:\n      minClassStanding: Jr\n", `line 2: CSCI-1100 invalid class standing "Jr"`},
+	}
+
+	for _, test := range tests {
+		source := NewMemorySource(map[string][]byte{DatasetCatalogYAML: []byte(test.data)})
+		_, err := YAMLAdapter{}.Load(context.Background(), source)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package workers
+
+import (
+	"sort"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+)
+
+// The changes between the stored catalog and the catalog source data. Removed
+// courses are no longer in the source and get retired, restored courses are
+// retired courses back in the source. Modified courses changed in any way,
+// including renames and prerequisite changes
+type CatalogDiff struct {
+	Added                []string       `bson:"added" json:"added"`
+	Removed              []string       `bson:"removed" json:"removed"`
+	Restored             []string       `bson:"restored" json:"restored"`
+	Renamed              []CourseRename `bson:"renamed" json:"renamed"`
+	PrerequisitesChanged []string       `bson:"prerequisitesChanged" json:"prerequisitesChanged"`
+	Modified             []string       `bson:"modified" json:"modified"`
+	Unchanged            int            `bson:"unchanged" json:"unchanged"`
+}
+
+type CourseRename struct {
+	Code    string `bson:"code" json:"code"`
+	OldName string `bson:"oldName" json:"oldName"`
+	NewName string `bson:"newName" json:"newName"`
+}
+
+// diffCatalog compares the existing and incoming courses keyed by code, every
+// list in the diff is sorted by code
+func diffCatalog(existing map[string]*course.CourseDB, incoming map[string]*course.CourseDB) CatalogDiff {
+	diff := CatalogDiff{
+		Added:                []string{},
+		Removed:              []string{},
+		Restored:             []string{},
+		Renamed:              []CourseRename{},
+		PrerequisitesChanged: []string{},
+		Modified:             []string{},
+	}
+
+	for _, code := range sortedCodes(incoming) {
+		c := incoming[code]
+		old, ok := existing[code]
+		if !ok {
+			diff.Added = append(diff.Added, code)
+			continue
+		}
+		if old.Retired {
+			diff.Restored = append(diff.Restored, code)
+			continue
+		}
+		if old.Equal(c) {
+			diff.Unchanged++
+			continue
+		}
+		diff.Modified = append(diff.Modified, code)
+		if old.Name != c.Name {
+			diff.Renamed = append(diff.Renamed, CourseRename{Code: code, OldName: old.Name, NewName: c.Name})
+		}
+		if !prerequisitesEqual(old, c) {
+			diff.PrerequisitesChanged = append(diff.PrerequisitesChanged, code)
+		}
+	}
+
+	for _, code := range sortedCodes(existing) {
+		if _, ok := incoming[code]; !ok && !existing[code].Retired {
+			diff.Removed = append(diff.Removed, code)
+		}
+	}
+
+	return diff
+}
+
+func prerequisitesEqual(a *course.CourseDB, b *course.CourseDB) bool {
+	if !a.PrerequisiteTree.Equal(b.PrerequisiteTree) {
+		return false
+	}
+	if len(a.Prerequisites) != len(b.Prerequisites) {
+		return false
+	}
+	for i := range a.Prerequisites {
+		if len(a.Prerequisites[i]) != len(b.Prerequisites[i]) {
+			return false
+		}
+		for j := range a.Prerequisites[i] {
+			if a.Prerequisites[i][j] != b.Prerequisites[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func sortedCodes(courses map[string]*course.CourseDB) []string {
+	codes := make([]string, 0, len(courses))
+	for code := range courses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -1,114 +1,389 @@
 package workers
 
 import (
-	"container/list"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/huynchu/degree-planner-api/config"
 	"github.com/huynchu/degree-planner-api/internal/course"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+type courseStore interface {
+	FindCourseOverrides() ([]course.CourseOverrideDB, error)
+	FindAllCourses(catalogYear string) ([]course.CourseDB, error)
+	ReplaceCourseEquivalences(catalogYear string, groups [][]string) error
+	BulkWriteCourses(ctx context.Context, models []mongo.WriteModel) (*mongo.BulkWriteResult, error)
+}
+
+type syncRunStore interface {
+	CreateSyncRun(run *SyncRunDB) (string, error)
+}
+
+type sourceStateStore interface {
+	FindSourceStates(catalogYear string) (map[string]SourceVersion, error)
+	UpdateSourceStates(catalogYear string, versions map[string]SourceVersion) error
+}
+
 type CourseDataWorker struct {
-	db *mongo.Database
+	source         CatalogSource
+	adapter        CatalogAdapter
+	courseStorage  courseStore
+	syncRunStorage syncRunStore
+	stateStorage   sourceStateStore
+
+	// Called after every sync that changed the courses collection
+	onSync []func(run *SyncRunDB)
 }
 
-func NewCourseDataWorker(db *mongo.Database) *CourseDataWorker {
+func NewCourseDataWorker(db *mongo.Database, source CatalogSource, adapter CatalogAdapter) *CourseDataWorker {
 	return &CourseDataWorker{
-		db: db,
+		source:         source,
+		adapter:        adapter,
+		courseStorage:  course.NewCourseStorage(db),
+		syncRunStorage: NewSyncRunStorage(db),
+		stateStorage:   NewSourceStateStorage(db),
 	}
 }
 
-func (w *CourseDataWorker) Run() {
-	courseData := make(map[string]*course.CourseDB)
-	populateCourseData(courseData)
+type RunOptions struct {
+	// Compute the catalog diff without writing anything to mongo
+	DryRun bool
+	// Progress output, defaults to stdout
+	Out io.Writer
+	// Academic catalog year the source data belongs to, i.e 2024-2025
+	CatalogYear string
+	// Sync even if the source data and course overrides did not change since
+	// the last run, i.e to restore courses edited in mongo by hand
+	Force bool
+	// What started the run, i.e SyncTriggerCLI
+	Trigger string
+	// Notified of the run stages, optional
+	Progress ProgressReporter
+}
 
-	courseCollection := w.db.Collection("courses")
+// Number of course writes per bulk write, progress is reported per batch
+const writeBatchSize = 500
+
+// Run syncs the courses collection with the catalog source data and records
+// the run along with the catalog diff in the catalog_sync_runs collection.
+// Canceling ctx stops the run between stages and write batches. The run is
+// returned even if the sync failed
+func (w *CourseDataWorker) Run(ctx context.Context, opts RunOptions) (*SyncRunDB, error) {
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if opts.Progress == nil {
+		opts.Progress = nopProgress{}
+	}
 
-	// Bulk update(upsert) courses
-	models := []mongo.WriteModel{}
+	run := SyncRunDB{
+		StartedAt:   time.Now(),
+		Status:      SyncRunSucceeded,
+		DryRun:      opts.DryRun,
+		Trigger:     opts.Trigger,
+		CatalogYear: opts.CatalogYear,
+	}
+
+	syncErr := w.sync(ctx, &run, opts, out)
+	run.FinishedAt = time.Now()
+	if syncErr != nil {
+		fmt.Fprintln(out, "Error syncing course data:", syncErr)
+		run.Status = SyncRunFailed
+		if errors.Is(syncErr, context.Canceled) {
+			run.Status = SyncRunCanceled
+		}
+		run.Error = syncErr.Error()
+	}
+
+	if opts.DryRun {
+		return &run, syncErr
+	}
+
+	id, err := w.syncRunStorage.CreateSyncRun(&run)
+	if err != nil {
+		fmt.Fprintln(out, "Error recording sync run:", err)
+		if syncErr == nil {
+			return &run, fmt.Errorf("recording sync run: %w", err)
+		}
+		return &run, syncErr
+	}
+	run.ID, _ = primitive.ObjectIDFromHex(id)
+
+	if run.Status == SyncRunSucceeded {
+		for _, fn := range w.onSync {
+			fn(&run)
+		}
+	}
+
+	return &run, syncErr
+}
+
+// OnSync registers fn to be called after every sync that changed the courses
+// collection, i.e to refresh in memory course indexes. Register before any run
+func (w *CourseDataWorker) OnSync(fn func(run *SyncRunDB)) {
+	w.onSync = append(w.onSync, fn)
+}
+
+func (w *CourseDataWorker) sync(ctx context.Context, run *SyncRunDB, opts RunOptions, out io.Writer) error {
+	progress := opts.Progress
+	progress.Stage(SyncStageFetching)
+
+	// Admin overrides are merged over the source data, editing them changes
+	// the synced courses like a source change
+	overrides, err := w.courseStorage.FindCourseOverrides()
+	if err != nil {
+		return fmt.Errorf("fetching course overrides: %w", err)
+	}
+	overridesHash, err := courseOverridesHash(overrides, opts.CatalogYear)
+	if err != nil {
+		return fmt.Errorf("hashing course overrides: %w", err)
+	}
+
+	// Skip the sync if no dataset changed since the last successful run of the
+	// same schema version and course overrides
+	since := map[string]SourceVersion{}
+	if !opts.Force {
+		states, err := w.stateStorage.FindSourceStates(opts.CatalogYear)
+		if err != nil {
+			return fmt.Errorf("fetching source states: %w", err)
+		}
+		since = currentSourceVersions(states, catalogSchemaVersion, overridesHash)
+	}
+	datasets := w.adapter.Datasets()
+	data, versions, dataChanged, err := fetchCatalogData(ctx, w.source, datasets, since)
+	if err != nil {
+		return err
+	}
+	stampSourceVersions(versions, catalogSchemaVersion, overridesHash)
+	progress.Progress(len(datasets), len(datasets))
+	if !dataChanged {
+		fmt.Fprintln(out, "Catalog data unchanged since the last run: skipping sync")
+		run.Status = SyncRunUnchanged
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	progress.Stage(SyncStageParsing)
+	catalog, err := w.adapter.Load(ctx, NewMemorySource(data))
+	if err != nil {
+		return err
+	}
+	courseData := catalog.Courses
+	run.CourseCount = len(courseData)
 	for _, c := range courseData {
-		filter := bson.M{"code": c.Code}
+		c.CatalogYear = opts.CatalogYear
+		c.Subject, c.Level = course.SubjectAndLevel(c.Code)
+	}
+	progress.Progress(len(courseData), len(courseData))
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	progress.Stage(SyncStageCrossListing)
+	crossListingGroups := crossListingGroups(catalog.CrossListings)
+	progress.Progress(len(crossListingGroups), len(crossListingGroups))
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	progress.Stage(SyncStageWriting)
+
+	// Merge admin overrides over the source data
+	run.Overrides = applyCourseOverrides(courseData, opts.CatalogYear, overrides)
+	fmt.Fprintln(out, "Applied", len(run.Overrides), "course overrides")
+	for _, o := range run.Overrides {
+		fmt.Fprintln(out, "  "+o.Code+":", strings.Join(o.Fields, ", "))
+	}
+
+	// Diff against the stored courses of the same catalog year
+	existingCourses, err := w.courseStorage.FindAllCourses(opts.CatalogYear)
+	if err != nil {
+		return fmt.Errorf("fetching existing courses: %w", err)
+	}
+	existing := make(map[string]*course.CourseDB, len(existingCourses))
+	for i := range existingCourses {
+		existing[existingCourses[i].Code] = &existingCourses[i]
+	}
+	run.Diff = diffCatalog(existing, courseData)
+
+	fmt.Fprintln(out, "Number of courses:", len(courseData))
+	fmt.Fprintln(out, "Added", len(run.Diff.Added), "courses")
+	fmt.Fprintln(out, "Removed", len(run.Diff.Removed), "courses")
+	fmt.Fprintln(out, "Restored", len(run.Diff.Restored), "courses")
+	fmt.Fprintln(out, "Renamed", len(run.Diff.Renamed), "courses")
+	fmt.Fprintln(out, "Prerequisites changed for", len(run.Diff.PrerequisitesChanged), "courses")
+	fmt.Fprintln(out, "Modified", len(run.Diff.Modified), "courses")
+
+	if opts.DryRun {
+		fmt.Fprintln(out, "Dry run: skipping writes")
+		return nil
+	}
+
+	// Bulk update(upsert) added, restored and modified courses
+	models := []mongo.WriteModel{}
+	changed := append(append([]string{}, run.Diff.Added...), run.Diff.Restored...)
+	changed = append(changed, run.Diff.Modified...)
+	for _, code := range changed {
+		c := courseData[code]
+		filter := bson.M{"code": c.Code, "catalogYear": course.CatalogYearFilter(c.CatalogYear)}
 		update := bson.M{"$set": bson.M{
-			"name":          c.Name,
-			"prerequisites": c.Prerequisites,
-			"corequisites":  c.Corequisites,
-			"crossListings": c.CrossListings,
+			"catalogYear":      c.CatalogYear,
+			"subject":          c.Subject,
+			"level":            c.Level,
+			"name":             c.Name,
+			"description":      c.Description,
+			"attributes":       c.Attributes,
+			"prerequisites":    c.Prerequisites,
+			"prerequisiteTree": c.PrerequisiteTree,
+			"corequisites":     c.Corequisites,
+			"crossListings":    c.CrossListings,
+			"offered":          c.Offered,
+			"credits":          c.Credits,
+			"restrictions":     c.Restrictions,
+		}, "$unset": bson.M{
+			"retired":   "",
+			"retiredAt": "",
 		}}
 		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
 	}
 
-	result, err := courseCollection.BulkWrite(context.Background(), models)
+	// Retire removed courses, they are kept since degree plans reference them
+	if len(run.Diff.Removed) > 0 {
+		filter := bson.M{"code": bson.M{"$in": run.Diff.Removed}, "catalogYear": course.CatalogYearFilter(opts.CatalogYear)}
+		update := bson.M{"$set": bson.M{
+			"retired":   true,
+			"retiredAt": run.StartedAt,
+		}}
+		models = append(models, mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(update))
+	}
+
+	if len(models) > 0 {
+		var matched, upserted, modified int64
+		progress.Progress(0, len(models))
+		for start := 0; start < len(models); start += writeBatchSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			end := start + writeBatchSize
+			if end > len(models) {
+				end = len(models)
+			}
+			result, err := w.courseStorage.BulkWriteCourses(ctx, models[start:end])
+			if err != nil {
+				return fmt.Errorf("bulk writing course data: %w", err)
+			}
+			matched += result.MatchedCount
+			upserted += result.UpsertedCount
+			modified += result.ModifiedCount
+			progress.Progress(end, len(models))
+		}
+
+		fmt.Fprintln(out, "Bulk write result:")
+		fmt.Fprintln(out, "Matched", matched, "documents")
+		fmt.Fprintln(out, "Upserted", upserted, "documents")
+		fmt.Fprintln(out, "Modified", modified, "documents")
+	}
+
+	// Persist cross-listing groups as course equivalences
+	err = w.courseStorage.ReplaceCourseEquivalences(opts.CatalogYear, crossListingGroups)
+	if err != nil {
+		return fmt.Errorf("writing course equivalences: %w", err)
+	}
+	fmt.Fprintln(out, "Number of course equivalences:", len(crossListingGroups))
+
+	// Remember the ingested source versions for the next run
+	err = w.stateStorage.UpdateSourceStates(opts.CatalogYear, versions)
 	if err != nil {
-		fmt.Println("Error bulk writing course data:", err)
-		return
+		return fmt.Errorf("writing source states: %w", err)
 	}
 
-	fmt.Println("Number of courses:", len(courseData))
-	fmt.Println("Bulk write result:")
-	fmt.Println("Matched", result.MatchedCount, "documents")
-	fmt.Println("Upserted", result.UpsertedCount, "documents")
-	fmt.Println("Modified", result.ModifiedCount, "documents")
-	fmt.Println("UpsertedIds", result.UpsertedIDs)
+	return nil
 }
 
 type courseJson struct {
-	Csre string `json:"csre"` // i.e 1200
-	Name string `json:"name"` // i.e Data Structures
-	Sbj  string `json:"subj"` // i.e CSCI
-	// Desc string `json:"description"`
+	Csre    string          `json:"csre"` // i.e 1200
+	Name    string          `json:"name"` // i.e Data Structures
+	Sbj     string          `json:"subj"` // i.e CSCI
+	Desc    string          `json:"description"`
+	Offered string          `json:"offered"` // i.e Fall and spring terms annually.
+	Credits json.RawMessage `json:"credits"` // i.e 4, "1-4" or {"min": 1, "max": 4}
 }
 
 type coursePrerequisiteJson struct {
-	// Atributes     []string     `json:"attributes"`
-	Corequisites  []string      `json:"corequisites"`
-	CrossListings []string      `json:"cross_listings"`
-	Prerequisites *Prerequisite `json:"prerequisites"`
+	Attributes    []string          `json:"attributes"` // i.e [Communication Intensive]
+	Corequisites  []string          `json:"corequisites"`
+	CrossListings []string          `json:"cross_listings"`
+	Prerequisites *Prerequisite     `json:"prerequisites"`
+	Restrictions  *restrictionsJson `json:"restrictions"`
+}
+
+type restrictionsJson struct {
+	Classification []string `json:"classification"` // i.e [Junior Senior]
+	Major          []string `json:"major"`          // i.e [CSCI ITWS]
+	Permission     bool     `json:"permission"`     // instructor permission required
 }
 
 type Prerequisite struct {
 	Course string         `json:"course"`
 	Type   string         `json:"type"`
 	Nested []Prerequisite `json:"nested,omitempty"`
+
+	// Course qualifiers
+	MinGrade   string `json:"min_grade,omitempty"`  // i.e C
+	Concurrent bool   `json:"concurrent,omitempty"` // may be taken concurrently
 }
 
-func populateCourseData(courseData map[string]*course.CourseDB) error {
+// populateCourseData fills courseData from the catalog and prereq data of the
+// source and returns the cross-listings of every course in the prereq data
+func populateCourseData(ctx context.Context, source CatalogSource, courseData map[string]*course.CourseDB) (map[string][]string, error) {
 	// Get course catalog data
-	catalogData, err := fetchCourseCatalogData()
+	catalogData, err := source.Fetch(ctx, DatasetCatalog)
 	if err != nil {
-		fmt.Println("Error fetching course catalog data:", err)
-		return err
+		return nil, fmt.Errorf("fetching course catalog data: %w", err)
 	}
 
 	// Decode course catalog data
 	courseDataMap := make(map[string]courseJson)
 	err = json.Unmarshal(catalogData, &courseDataMap)
 	if err != nil {
-		fmt.Println("Error unmarshalling response body:", err)
-		return err
+		return nil, fmt.Errorf("decoding course catalog data: %w", err)
 	}
 
 	// Get course prereq data
-	prereqData, err := fetchCoursePrereqData()
+	prereqData, err := source.Fetch(ctx, DatasetPrereqs)
 	if err != nil {
-		fmt.Println("Error fetching course prereq data:", err)
-		return err
+		return nil, fmt.Errorf("fetching course prereq data: %w", err)
 	}
 
 	// Decode course prereq data
 	coursePrereqDataMap := make(map[string]coursePrerequisiteJson)
 	err = json.Unmarshal(prereqData, &coursePrereqDataMap)
 	if err != nil {
-		fmt.Println("Error unmarshalling response body:", err)
-		return err
+		return nil, fmt.Errorf("decoding course prereq data: %w", err)
+	}
+
+	crossListings := make(map[string][]string, len(coursePrereqDataMap))
+	for key, cprq := range coursePrereqDataMap {
+		crossListings[key] = cprq.CrossListings
+	}
+	crossListingGroup := make(map[string][]string)
+	for _, group := range crossListingGroups(crossListings) {
+		for _, code := range group {
+			crossListingGroup[code] = group
+		}
 	}
 
 	// populate courseData with course catalog data
@@ -116,9 +391,12 @@ func populateCourseData(courseData map[string]*course.CourseDB) error {
 		newDBCourse := &course.CourseDB{
 			Code:          key,
 			Name:          c.Name,
+			Description:   strings.TrimSpace(c.Desc),
 			Prerequisites: [][]string{},
 			Corequisites:  []string{},
 			CrossListings: []string{},
+			Offered:       course.ParseOfferedTerms(c.Offered),
+			Credits:       parseCredits(c.Credits),
 		}
 		courseData[key] = newDBCourse
 	}
@@ -127,20 +405,27 @@ func populateCourseData(courseData map[string]*course.CourseDB) error {
 	for key, cprq := range coursePrereqDataMap {
 		c, ok := courseData[key]
 		if ok {
+			c.Attributes = normalizeAttributes(cprq.Attributes)
 			if cprq.Corequisites != nil {
 				c.Corequisites = cprq.Corequisites
 			}
 			if cprq.Prerequisites != nil {
 				c.Prerequisites = cprq.Prerequisites.TransformPrereq()
+				c.PrerequisiteTree = cprq.Prerequisites.Expression()
 			}
 			if cprq.CrossListings != nil {
 				c.CrossListings = cprq.CrossListings
 			}
+			if cprq.Restrictions != nil {
+				c.Restrictions = cprq.Restrictions.Restrictions()
+			}
 		} else {
 			hasCrossListings := cprq.CrossListings != nil && len(cprq.CrossListings) > 0
 			if hasCrossListings {
-				courseCrossListing := getCrossListings(key, coursePrereqDataMap)
-				for _, crossListing := range courseCrossListing {
+				for _, crossListing := range crossListingGroup[key] {
+					if crossListing == key {
+						continue
+					}
 					_, ok := courseData[crossListing]
 					if ok {
 						course := course.CourseDB{
@@ -149,16 +434,21 @@ func populateCourseData(courseData map[string]*course.CourseDB) error {
 							Prerequisites: [][]string{},
 							Corequisites:  []string{},
 							CrossListings: []string{},
+							Attributes:    normalizeAttributes(cprq.Attributes),
 						}
 						if cprq.Corequisites != nil {
 							course.Corequisites = cprq.Corequisites
 						}
 						if cprq.Prerequisites != nil {
 							course.Prerequisites = cprq.Prerequisites.TransformPrereq()
+							course.PrerequisiteTree = cprq.Prerequisites.Expression()
 						}
 						if cprq.CrossListings != nil {
 							course.CrossListings = cprq.CrossListings
 						}
+						if cprq.Restrictions != nil {
+							course.Restrictions = cprq.Restrictions.Restrictions()
+						}
 						courseData[key] = &course
 						break
 					}
@@ -170,118 +460,117 @@ func populateCourseData(courseData map[string]*course.CourseDB) error {
 	}
 
 	// no errors
-	return nil
+	return crossListings, nil
 }
 
-func getCrossListings(ccode string, courseData map[string]coursePrerequisiteJson) []string {
-	visited := make(map[string]bool)
-
-	queue := list.New()
-	queue.PushBack(ccode)
-	for queue.Len() > 0 {
-		e := queue.Front()
-		queue.Remove(e)
-		courseCode := e.Value.(string)
-		visited[courseCode] = true
-		cdata, ok := courseData[courseCode]
-		if ok {
-			for _, crossListing := range cdata.CrossListings {
-				inQueue := false
-				for e := queue.Front(); e != nil; e = e.Next() {
-					if e.Value.(string) == crossListing {
-						inQueue = true
-						break
-					}
-				}
-				if !visited[crossListing] && !inQueue {
-					queue.PushBack(crossListing)
-				}
-			}
-		}
+// parseCredits reads the credits of a course, ranges use the maximum credits.
+// Returns 0 if the credits are missing or malformed
+func parseCredits(raw json.RawMessage) int {
+	if len(raw) == 0 {
+		return 0
 	}
 
-	crossListings := make([]string, 0, len(visited))
-	for key := range visited {
-		if key != ccode {
-			crossListings = append(crossListings, key)
-		}
+	var credits int
+	if err := json.Unmarshal(raw, &credits); err == nil {
+		return credits
 	}
-	return crossListings
-}
 
-func fetchCourseCatalogData() ([]byte, error) {
-	env, err := config.LoadConfig()
-	if err != nil {
-		return nil, err
+	var creditRange struct {
+		Max int `json:"max"`
 	}
-	rootDir, err := os.Getwd()
-	if err != nil {
-		return nil, err
+	if err := json.Unmarshal(raw, &creditRange); err == nil {
+		return creditRange.Max
 	}
-	filepath := filepath.Join(rootDir, "internal", "data", "catalog.json")
 
-	switch env.GO_ENV {
-	case "dev":
-		return fetchJsonDataFromLocalFile(filepath)
-	case "prod":
-		return fetchJsonDataFromGitHub(env.COURSE_DATA_URL)
-	default:
-		return fetchJsonDataFromLocalFile(filepath)
+	var creditStr string
+	if err := json.Unmarshal(raw, &creditStr); err == nil {
+		return parseCreditsString(creditStr)
 	}
+
+	return 0
 }
 
-func fetchCoursePrereqData() ([]byte, error) {
-	env, err := config.LoadConfig()
+// parseCreditsString reads credits i.e "4" or "1-4", returns 0 if malformed
+func parseCreditsString(s string) int {
+	parts := strings.Split(s, "-")
+	credits, err := strconv.Atoi(strings.TrimSpace(parts[len(parts)-1]))
 	if err != nil {
-		return nil, err
+		return 0
 	}
-	rootDir, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
-	filepath := filepath.Join(rootDir, "internal", "data", "prereq_data.json")
+	return credits
+}
 
-	switch env.GO_ENV {
-	case "dev":
-		return fetchJsonDataFromLocalFile(filepath)
-	case "prod":
-		return fetchJsonDataFromGitHub(env.COURSE_PREREQ_DATA_URL)
-	default:
-		return fetchJsonDataFromLocalFile(filepath)
+// Restrictions converts the source restrictions, the minimum class standing
+// is the lowest classification allowed
+func (r restrictionsJson) Restrictions() *course.CourseRestrictions {
+	restrictions := &course.CourseRestrictions{
+		Majors:             r.Major,
+		PermissionRequired: r.Permission,
 	}
+	for _, standing := range []string{course.StandingFreshman, course.StandingSophomore, course.StandingJunior, course.StandingSenior} {
+		for _, classification := range r.Classification {
+			if strings.EqualFold(classification, standing) && restrictions.MinClassStanding == "" {
+				restrictions.MinClassStanding = standing
+			}
+		}
+	}
+	return restrictions
 }
 
-func fetchJsonDataFromLocalFile(filePath string) ([]byte, error) {
-	jsonFile, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
+// normalizeAttributes trims and sorts the attributes so catalog diffs do not
+// depend on the source order, returns nil if there are no attributes
+func normalizeAttributes(attributes []string) []string {
+	var res []string
+	seen := make(map[string]bool, len(attributes))
+	for _, a := range attributes {
+		a = strings.TrimSpace(a)
+		if a == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		res = append(res, a)
 	}
+	sort.Strings(res)
+	return res
+}
 
-	defer jsonFile.Close()
-
-	dataBytes, err := io.ReadAll(jsonFile)
-	if err != nil {
-		return nil, err
+// applyCourseOverrides merges the overrides of the catalog year over the
+// courses and returns the applied overrides. Overrides of courses missing from
+// the source data are skipped
+func applyCourseOverrides(courseData map[string]*course.CourseDB, catalogYear string, overrides []course.CourseOverrideDB) []AppliedOverride {
+	applied := []AppliedOverride{}
+	for i := range overrides {
+		o := &overrides[i]
+		c, ok := courseData[o.Code]
+		if !ok || !o.AppliesTo(catalogYear) {
+			continue
+		}
+		fields := o.Apply(c)
+		if len(fields) > 0 {
+			applied = append(applied, AppliedOverride{ID: o.ID, Code: o.Code, Fields: fields})
+		}
 	}
-
-	return dataBytes, nil
+	return applied
 }
 
-func fetchJsonDataFromGitHub(url string) ([]byte, error) {
-	// Make the GET request
-	response, err := http.Get(url)
-	if err != nil {
-		return nil, err
+// courseOverridesHash returns the hash of the overrides of the catalog year,
+// empty if there are none
+func courseOverridesHash(overrides []course.CourseOverrideDB, catalogYear string) (string, error) {
+	applicable := []course.CourseOverrideDB{}
+	for _, o := range overrides {
+		if o.AppliesTo(catalogYear) {
+			applicable = append(applicable, o)
+		}
+	}
+	if len(applicable) == 0 {
+		return "", nil
 	}
 
-	defer response.Body.Close()
-
-	data, err := io.ReadAll(response.Body)
+	data, err := json.Marshal(applicable)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	return data, nil
+	return hashData(data), nil
 }
 
 func (p Prerequisite) TransformPrereqRecursive(res *[][]string) []string {
@@ -297,20 +586,46 @@ func (p Prerequisite) TransformPrereqRecursive(res *[][]string) []string {
 		}
 		return or
 	} else {
-		tmp := strings.Split(p.Course, " ")
-		course := tmp[0] + "-" + tmp[1]
-		return []string{course}
+		return []string{prereqCourseCode(p.Course)}
 	}
 }
 
 func (p Prerequisite) TransformPrereq() [][]string {
 	if p.Type == "course" {
-		tmp := strings.Split(p.Course, " ")
-		course := tmp[0] + "-" + tmp[1]
-		return [][]string{{course}}
+		return [][]string{{prereqCourseCode(p.Course)}}
 	} else {
 		res := [][]string{}
 		p.TransformPrereqRecursive(&res)
 		return res
 	}
 }
+
+// Expression converts the prerequisite into an expression tree without
+// flattening it, course codes are normalized i.e "CSCI 1200" -> "CSCI-1200"
+func (p Prerequisite) Expression() *course.PrerequisiteExpr {
+	expr := &course.PrerequisiteExpr{
+		Type: p.Type,
+	}
+	if p.Type == course.PrerequisiteAnd || p.Type == course.PrerequisiteOr {
+		expr.Nested = make([]course.PrerequisiteExpr, 0, len(p.Nested))
+		for _, prereq := range p.Nested {
+			expr.Nested = append(expr.Nested, *prereq.Expression())
+		}
+	} else {
+		expr.Course = prereqCourseCode(p.Course)
+		expr.MinGrade = p.MinGrade
+		expr.Concurrent = p.Concurrent
+	}
+	return expr
+}
+
+// prereqCourseCode converts a prerequisite course to a course code,
+// i.e "CSCI 1200" -> "CSCI-1200". A course that is not a subject and number is
+// returned as is and reported by the catalog validation
+func prereqCourseCode(c string) string {
+	tmp := strings.Fields(c)
+	if len(tmp) != 2 {
+		return strings.TrimSpace(c)
+	}
+	return tmp[0] + "-" + tmp[1]
+}
@@ -1,10 +1,17 @@
 package workers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/huynchu/degree-planner-api/internal/course"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestCourseCompare(t *testing.T) {
@@ -43,3 +50,360 @@ func TestCourseCompare(t *testing.T) {
 		t.Error("course1 != course1BSONDecoded")
 	}
 }
+
+func TestPrerequisiteExpression(t *testing.T) {
+	// (CSCI 1100 and MATH 1010) or CSCI 1200
+	data := `{
+		"type": "or",
+		"nested": [
+			{"type": "and", "nested": [
+				{"type": "course", "course": "CSCI 1100"},
+				{"type": "course", "course": "MATH 1010"}
+			]},
+			{"type": "course", "course": "CSCI 1200"}
+		]
+	}`
+
+	var prereq Prerequisite
+	err := json.Unmarshal([]byte(data), &prereq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expr := prereq.Expression()
+	expected := "(CSCI-1100 and MATH-1010) or CSCI-1200"
+	if expr.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, expr.String())
+	}
+
+	// Taking CSCI-1200 alone satisfies the tree
+	if !expr.SatisfiedBy(map[string]bool{"CSCI-1200": true}) {
+		t.Error("Expected CSCI-1200 to satisfy the prerequisite expression")
+	}
+}
+
+func TestDiffCatalog(t *testing.T) {
+	existing := map[string]*course.CourseDB{
+		"CSCI-1100": {Code: "CSCI-1100", Name: "Computer Science I"},
+		"CSCI-1200": {Code: "CSCI-1200", Name: "Data Structures", Prerequisites: [][]string{{"CSCI-1100"}}},
+		"CSCI-2200": {Code: "CSCI-2200", Name: "Foundations of CS"},
+		"CSCI-4969": {Code: "CSCI-4969", Name: "Topics"},
+	}
+	incoming := map[string]*course.CourseDB{
+		"CSCI-1100": {Code: "CSCI-1100", Name: "Computer Science I"},
+		"CSCI-1200": {Code: "CSCI-1200", Name: "Data Structures", Prerequisites: [][]string{{"CSCI-1100", "ENGR-1200"}}},
+		"CSCI-2200": {Code: "CSCI-2200", Name: "Foundations of Computer Science"},
+		"CSCI-2300": {Code: "CSCI-2300", Name: "Introduction to Algorithms"},
+	}
+
+	diff := diffCatalog(existing, incoming)
+
+	if len(diff.Added) != 1 || diff.Added[0] != "CSCI-2300" {
+		t.Errorf("Expected added [CSCI-2300], got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "CSCI-4969" {
+		t.Errorf("Expected removed [CSCI-4969], got %v", diff.Removed)
+	}
+	if len(diff.Renamed) != 1 || diff.Renamed[0].Code != "CSCI-2200" {
+		t.Errorf("Expected renamed [CSCI-2200], got %v", diff.Renamed)
+	}
+	if len(diff.PrerequisitesChanged) != 1 || diff.PrerequisitesChanged[0] != "CSCI-1200" {
+		t.Errorf("Expected prerequisites changed [CSCI-1200], got %v", diff.PrerequisitesChanged)
+	}
+	if len(diff.Modified) != 2 || diff.Unchanged != 1 {
+		t.Errorf("Expected 2 modified and 1 unchanged, got %v and %v", diff.Modified, diff.Unchanged)
+	}
+}
+
+func TestDiffCatalog__Retired(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *course.CourseDB
+		incoming *course.CourseDB
+		removed  []string
+		restored []string
+		modified []string
+	}{
+		{
+			"removed course is retired",
+			&course.CourseDB{Code: "CSCI-4969", Name: "Topics"},
+			nil,
+			[]string{"CSCI-4969"}, []string{}, []string{},
+		},
+		{
+			"retired course stays retired",
+			&course.CourseDB{Code: "CSCI-4969", Name: "Topics", Retired: true},
+			nil,
+			[]string{}, []string{}, []string{},
+		},
+		{
+			"retired course reappears",
+			&course.CourseDB{Code: "CSCI-4969", Name: "Topics", Retired: true},
+			&course.CourseDB{Code: "CSCI-4969", Name: "Topics"},
+			[]string{}, []string{"CSCI-4969"}, []string{},
+		},
+		{
+			"retired course reappears changed",
+			&course.CourseDB{Code: "CSCI-4969", Name: "Topics", Retired: true},
+			&course.CourseDB{Code: "CSCI-4969", Name: "Topics in Computer Science"},
+			[]string{}, []string{"CSCI-4969"}, []string{},
+		},
+		{
+			"current course stays current",
+			&course.CourseDB{Code: "CSCI-4969", Name: "Topics"},
+			&course.CourseDB{Code: "CSCI-4969", Name: "Topics in Computer Science"},
+			[]string{}, []string{}, []string{"CSCI-4969"},
+		},
+	}
+
+	for _, test := range tests {
+		existing := map[string]*course.CourseDB{test.existing.Code: test.existing}
+		incoming := map[string]*course.CourseDB{}
+		if test.incoming != nil {
+			incoming[test.incoming.Code] = test.incoming
+		}
+
+		diff := diffCatalog(existing, incoming)
+		if !reflect.DeepEqual(diff.Removed, test.removed) || !reflect.DeepEqual(diff.Restored, test.restored) || !reflect.DeepEqual(diff.Modified, test.modified) {
+			t.Errorf("%s: expected removed %v, restored %v and modified %v, got %v, %v and %v",
+				test.name, test.removed, test.restored, test.modified, diff.Removed, diff.Restored, diff.Modified)
+		}
+		if len(diff.Added) != 0 {
+			t.Errorf("%s: expected no added courses, got %v", test.name, diff.Added)
+		}
+	}
+}
+
+func TestApplyCourseOverrides(t *testing.T) {
+	credits := 3
+	prereqs := "CSCI-1100 or CSCI-1010"
+	courseData := map[string]*course.CourseDB{
+		"CSCI-1200": {Code: "CSCI-1200", Name: "Data Structures", Credits: 4},
+	}
+	overrides := []course.CourseOverrideDB{
+		{Code: "CSCI-1200", Name: "Data Structures and Algorithms", Prerequisites: &prereqs},
+		{Code: "CSCI-1200", CatalogYear: "2023-2024", Credits: &credits},
+		{Code: "CSCI-9999", Name: "Not in the catalog"},
+	}
+
+	applied := applyCourseOverrides(courseData, "2024-2025", overrides)
+	c := courseData["CSCI-1200"]
+	if c.Name != "Data Structures and Algorithms" || c.Credits != 4 {
+		t.Errorf("Expected only the override of every catalog year to apply, got %+v", c)
+	}
+	if c.PrerequisiteTree.String() != prereqs || len(c.Prerequisites) != 1 || len(c.Prerequisites[0]) != 2 {
+		t.Errorf("Expected overridden prerequisites, got %v and %v", c.PrerequisiteTree, c.Prerequisites)
+	}
+	if len(applied) != 1 || applied[0].Code != "CSCI-1200" || len(applied[0].Fields) != 2 {
+		t.Errorf("Expected one applied override replacing 2 fields, got %+v", applied)
+	}
+}
+
+func TestCourseOverridesHash__NextRunSyncs(t *testing.T) {
+	datasets := []string{DatasetCatalog, DatasetPrereqs}
+	source := NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+		DatasetPrereqs: []byte(testPrereqData),
+	})
+	credits := 3
+	overrides := []course.CourseOverrideDB{
+		{Code: "CSCI-1200", Name: "Data Structures and Algorithms"},
+		{Code: "CSCI-1100", CatalogYear: "2023-2024", Credits: &credits},
+	}
+
+	// The last run ingested the data with the overrides
+	hash, err := courseOverridesHash(overrides, "2024-2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, versions, _, err := fetchCatalogData(context.Background(), source, datasets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stampSourceVersions(versions, catalogSchemaVersion, hash)
+
+	nextRunChanged := func(overrides []course.CourseOverrideDB) bool {
+		hash, err := courseOverridesHash(overrides, "2024-2025")
+		if err != nil {
+			t.Fatal(err)
+		}
+		since := currentSourceVersions(versions, catalogSchemaVersion, hash)
+		_, _, changed, err := fetchCatalogData(context.Background(), source, datasets, since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changed
+	}
+
+	if nextRunChanged(overrides) {
+		t.Error("Expected the next run to skip without override changes")
+	}
+
+	edited := append([]course.CourseOverrideDB{}, overrides...)
+	edited[0].Name = "Data Structures"
+	if !nextRunChanged(edited) {
+		t.Error("Expected the next run to sync after editing an override")
+	}
+	if !nextRunChanged(overrides[1:]) {
+		t.Error("Expected the next run to sync after deleting an override")
+	}
+	if !nextRunChanged(append(overrides, course.CourseOverrideDB{Code: "MATH-1010", Name: "Calculus I"})) {
+		t.Error("Expected the next run to sync after creating an override")
+	}
+
+	// Overrides of another catalog year don't change the courses of this one
+	otherYear := append([]course.CourseOverrideDB{}, overrides...)
+	otherYear[1].Credits = nil
+	if nextRunChanged(otherYear) {
+		t.Error("Expected the next run to skip after editing an override of another catalog year")
+	}
+
+	if hash, _ := courseOverridesHash(nil, "2024-2025"); hash != "" {
+		t.Errorf("Expected an empty hash without overrides, got %q", hash)
+	}
+}
+
+func TestNormalizeAttributes(t *testing.T) {
+	tests := []struct {
+		attributes []string
+		expected   []string
+	}{
+		{nil, nil},
+		{[]string{}, nil},
+		{[]string{" ", ""}, nil},
+		{[]string{"HASS Inquiry", "Communication Intensive"}, []string{"Communication Intensive", "HASS Inquiry"}},
+		{[]string{" Communication Intensive ", "Communication Intensive"}, []string{"Communication Intensive"}},
+	}
+
+	for _, test := range tests {
+		if actual := normalizeAttributes(test.attributes); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%q: expected %q, got %q", test.attributes, test.expected, actual)
+		}
+	}
+}
+
+func TestDiffCatalog__Backfill(t *testing.T) {
+	// Courses written before the subject and level were stored
+	existing := map[string]*course.CourseDB{
+		"CSCI-4100": {Code: "CSCI-4100", Name: "Machine Learning"},
+	}
+	incoming := map[string]*course.CourseDB{
+		"CSCI-4100": {Code: "CSCI-4100", Name: "Machine Learning", Subject: "CSCI", Level: 4000},
+	}
+
+	diff := diffCatalog(existing, incoming)
+	if len(diff.Modified) != 1 || diff.Modified[0] != "CSCI-4100" {
+		t.Errorf("Expected the course to be rewritten, got %+v", diff)
+	}
+}
+
+// fakeCatalogStore keeps the courses of one catalog year and records every write
+type fakeCatalogStore struct {
+	courses      []course.CourseDB
+	writes       []mongo.WriteModel
+	equivalences [][]string
+	syncRuns     []*SyncRunDB
+	states       map[string]SourceVersion
+	stateWrites  int
+}
+
+func (s *fakeCatalogStore) FindCourseOverrides() ([]course.CourseOverrideDB, error) {
+	return nil, nil
+}
+
+func (s *fakeCatalogStore) FindAllCourses(catalogYear string) ([]course.CourseDB, error) {
+	return s.courses, nil
+}
+
+func (s *fakeCatalogStore) ReplaceCourseEquivalences(catalogYear string, groups [][]string) error {
+	s.equivalences = groups
+	return nil
+}
+
+func (s *fakeCatalogStore) BulkWriteCourses(ctx context.Context, models []mongo.WriteModel) (*mongo.BulkWriteResult, error) {
+	s.writes = append(s.writes, models...)
+	return &mongo.BulkWriteResult{}, nil
+}
+
+func (s *fakeCatalogStore) CreateSyncRun(run *SyncRunDB) (string, error) {
+	s.syncRuns = append(s.syncRuns, run)
+	return primitive.NewObjectID().Hex(), nil
+}
+
+func (s *fakeCatalogStore) FindSourceStates(catalogYear string) (map[string]SourceVersion, error) {
+	return s.states, nil
+}
+
+func (s *fakeCatalogStore) UpdateSourceStates(catalogYear string, versions map[string]SourceVersion) error {
+	s.states = versions
+	s.stateWrites++
+	return nil
+}
+
+func newTestCourseDataWorker(store *fakeCatalogStore) *CourseDataWorker {
+	source := NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+		DatasetPrereqs: []byte(testPrereqData),
+	})
+	return &CourseDataWorker{
+		source:         source,
+		adapter:        QuatalogAdapter{},
+		courseStorage:  store,
+		syncRunStorage: store,
+		stateStorage:   store,
+	}
+}
+
+func TestCourseDataWorker__DryRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{"dry run", true},
+		{"sync", false},
+	}
+
+	for _, test := range tests {
+		store := &fakeCatalogStore{courses: []course.CourseDB{
+			{Code: "CSCI-1100", Name: "Computer Science I", CatalogYear: "2024-2025"},
+			{Code: "CSCI-4969", Name: "Topics", CatalogYear: "2024-2025"},
+		}}
+		worker := newTestCourseDataWorker(store)
+		synced := 0
+		worker.OnSync(func(run *SyncRunDB) { synced++ })
+
+		var out bytes.Buffer
+		run, err := worker.Run(context.Background(), RunOptions{DryRun: test.dryRun, Out: &out, CatalogYear: "2024-2025"})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		// The diff is reported either way
+		if run.Status != SyncRunSucceeded || run.DryRun != test.dryRun {
+			t.Errorf("%s: expected a succeeded run, got %+v", test.name, run)
+		}
+		if len(run.Diff.Added) != 3 || strings.Join(run.Diff.Removed, ",") != "CSCI-4969" || len(run.Diff.Modified) != 1 {
+			t.Errorf("%s: unexpected diff %+v", test.name, run.Diff)
+		}
+		if !strings.Contains(out.String(), "Removed 1 courses") {
+			t.Errorf("%s: expected the diff in the output, got %q", test.name, out.String())
+		}
+
+		if test.dryRun {
+			if len(store.writes) != 0 || store.equivalences != nil || store.stateWrites != 0 || len(store.syncRuns) != 0 || synced != 0 {
+				t.Errorf("%s: expected no writes, got %v course writes, equivalences %v, %v source state writes, %v sync runs and %v sync callbacks",
+					test.name, len(store.writes), store.equivalences, store.stateWrites, len(store.syncRuns), synced)
+			}
+			if !strings.Contains(out.String(), "Dry run: skipping writes") {
+				t.Errorf("%s: expected the dry run notice, got %q", test.name, out.String())
+			}
+			continue
+		}
+
+		// 3 added and 1 modified course upserts and the retirement of the removed course
+		if len(store.writes) != 5 || len(store.equivalences) != 1 || store.stateWrites != 1 || len(store.syncRuns) != 1 || synced != 1 {
+			t.Errorf("%s: expected every write, got %v course writes, equivalences %v, %v source state writes, %v sync runs and %v sync callbacks",
+				test.name, len(store.writes), store.equivalences, store.stateWrites, len(store.syncRuns), synced)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package workers
+
+import "sort"
+
+// unionFind tracks disjoint sets of course codes, i.e cross-listed courses
+type unionFind struct {
+	parent map[string]string
+	size   map[string]int
+}
+
+func newUnionFind() *unionFind {
+	return &unionFind{
+		parent: make(map[string]string),
+		size:   make(map[string]int),
+	}
+}
+
+// find returns the root of the set of code, compressing the path to it
+func (u *unionFind) find(code string) string {
+	if _, ok := u.parent[code]; !ok {
+		u.parent[code] = code
+		u.size[code] = 1
+		return code
+	}
+
+	root := code
+	for u.parent[root] != root {
+		root = u.parent[root]
+	}
+	for code != root {
+		next := u.parent[code]
+		u.parent[code] = root
+		code = next
+	}
+	return root
+}
+
+// union merges the sets of a and b, the smaller set is attached to the larger
+func (u *unionFind) union(a string, b string) {
+	rootA, rootB := u.find(a), u.find(b)
+	if rootA == rootB {
+		return
+	}
+	if u.size[rootA] < u.size[rootB] {
+		rootA, rootB = rootB, rootA
+	}
+	u.parent[rootB] = rootA
+	u.size[rootA] += u.size[rootB]
+}
+
+// crossListingGroups returns every group of transitively cross-listed courses
+// in a single union-find pass, crossListings maps each course code to the codes
+// it is cross-listed with. Groups and the codes in them are sorted
+func crossListingGroups(crossListings map[string][]string) [][]string {
+	u := newUnionFind()
+	for code, listings := range crossListings {
+		for _, listing := range listings {
+			u.union(code, listing)
+		}
+	}
+
+	members := make(map[string][]string)
+	for code := range u.parent {
+		root := u.find(code)
+		members[root] = append(members[root], code)
+	}
+
+	groups := make([][]string, 0, len(members))
+	for _, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Strings(group)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+	return groups
+}
@@ -0,0 +1,50 @@
+package workers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCrossListingGroups(t *testing.T) {
+	crossListings := map[string][]string{
+		// Transitive: ITWS-4100 is only cross-listed with MATH-4100
+		"CSCI-4100": {"MATH-4100"},
+		"MATH-4100": {"CSCI-4100", "ITWS-4100"},
+		// Asymmetric listing, COGS-4420 is not in the data
+		"CSCI-4420": {"COGS-4420"},
+		"CSCI-1100": {},
+		"CSCI-1200": nil,
+	}
+
+	expected := [][]string{
+		{"COGS-4420", "CSCI-4420"},
+		{"CSCI-4100", "ITWS-4100", "MATH-4100"},
+	}
+	if groups := crossListingGroups(crossListings); !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected %v, got %v", expected, groups)
+	}
+}
+
+// syntheticCrossListings returns n courses cross-listed in chains of 3, i.e
+// SUBJ-00000 -> SUBJ-00001 -> SUBJ-00002
+func syntheticCrossListings(n int) map[string][]string {
+	crossListings := make(map[string][]string, n)
+	for i := 0; i < n; i++ {
+		code := fmt.Sprintf("SUBJ-%05d", i)
+		crossListings[code] = []string{}
+		if i%3 != 2 && i+1 < n {
+			crossListings[code] = append(crossListings[code], fmt.Sprintf("SUBJ-%05d", i+1))
+		}
+	}
+	return crossListings
+}
+
+func BenchmarkCrossListingGroups(b *testing.B) {
+	crossListings := syntheticCrossListings(10000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		crossListingGroups(crossListings)
+	}
+}
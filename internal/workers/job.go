@@ -0,0 +1,506 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	JOB_COLLECTION = "catalog_sync_jobs"
+)
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+const SyncTriggerAdmin = "admin"
+
+var (
+	ErrJobQueueFull     = errors.New("catalog sync job queue is full")
+	ErrJobNotFound      = errors.New("job not found")
+	ErrJobNotCancelable = errors.New("job already finished")
+	ErrJobAbandoned     = errors.New("replica running the job stopped")
+)
+
+// How long a replica holds its queued and running jobs without renewing them
+const jobLeaseTTL = CatalogSyncLockTTL
+
+// An admin triggered catalog sync. Jobs are stored in mongo so any replica can
+// report them, a cancel request is picked up by the replica running the job.
+// The replica holding a queued or running job renews its lease, jobs whose
+// lease expired are taken over by another replica
+type JobDB struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Status          string              `bson:"status" json:"status"`
+	Stage           string              `bson:"stage,omitempty" json:"stage,omitempty"`
+	Progress        JobProgress         `bson:"progress" json:"progress"`
+	Error           string              `bson:"error,omitempty" json:"error,omitempty"`
+	DryRun          bool                `bson:"dryRun,omitempty" json:"dryRun"`
+	Force           bool                `bson:"force,omitempty" json:"force"`
+	CatalogYear     string              `bson:"catalogYear,omitempty" json:"catalogYear,omitempty"`
+	SyncRunID       *primitive.ObjectID `bson:"syncRunID,omitempty" json:"syncRunID,omitempty"`
+	CancelRequested bool                `bson:"cancelRequested,omitempty" json:"cancelRequested"`
+	CreatedAt       time.Time           `bson:"createdAt" json:"createdAt"`
+	StartedAt       *time.Time          `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	FinishedAt      *time.Time          `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	Owner           string              `bson:"owner,omitempty" json:"owner,omitempty"`
+	LeaseExpiresAt  *time.Time          `bson:"leaseExpiresAt,omitempty" json:"leaseExpiresAt,omitempty"`
+}
+
+// Items done out of the total of the current stage
+type JobProgress struct {
+	Done  int `bson:"done" json:"done"`
+	Total int `bson:"total" json:"total"`
+}
+
+type JobStorage struct {
+	db *mongo.Database
+}
+
+func NewJobStorage(db *mongo.Database) *JobStorage {
+	return &JobStorage{
+		db: db,
+	}
+}
+
+func (s *JobStorage) CreateJob(job *JobDB) (string, error) {
+	collection := s.db.Collection(JOB_COLLECTION)
+
+	insertResult, err := collection.InsertOne(context.Background(), job)
+	if err != nil {
+		return "", err
+	}
+
+	return insertResult.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (s *JobStorage) FindJobByID(id string) (*JobDB, error) {
+	collection := s.db.Collection(JOB_COLLECTION)
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the job
+	var job JobDB
+	err = collection.FindOne(context.Background(), bson.M{"_id": objId}).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// UpdateJob sets the fields of the job and returns the updated job, i.e to
+// check whether a cancel was requested
+func (s *JobStorage) UpdateJob(id primitive.ObjectID, fields bson.M) (*JobDB, error) {
+	collection := s.db.Collection(JOB_COLLECTION)
+
+	var job JobDB
+	err := collection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": id},
+		bson.M{"$set": fields},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// RequestCancel flags a queued or running job for cancellation
+func (s *JobStorage) RequestCancel(id primitive.ObjectID) error {
+	collection := s.db.Collection(JOB_COLLECTION)
+
+	filter := bson.M{"_id": id, "status": bson.M{"$in": bson.A{JobQueued, JobRunning}}}
+	result, err := collection.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"cancelRequested": true}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrJobNotCancelable
+	}
+	return nil
+}
+
+// RenewJobLeases extends the lease of every queued or running job of the owner
+func (s *JobStorage) RenewJobLeases(owner string, expiresAt time.Time) error {
+	collection := s.db.Collection(JOB_COLLECTION)
+
+	filter := bson.M{"owner": owner, "status": bson.M{"$in": bson.A{JobQueued, JobRunning}}}
+	_, err := collection.UpdateMany(context.Background(), filter, bson.M{"$set": bson.M{"leaseExpiresAt": expiresAt}})
+	return err
+}
+
+// FindStaleJobs returns the queued and running jobs whose lease expired, i.e
+// the replica holding them was restarted. Jobs without a lease are stale too
+func (s *JobStorage) FindStaleJobs(now time.Time) ([]JobDB, error) {
+	collection := s.db.Collection(JOB_COLLECTION)
+
+	cursor, err := collection.Find(context.Background(), staleJobFilter(now))
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	jobs := []JobDB{}
+	err = cursor.All(context.Background(), &jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// ClaimStaleJob takes over the job if it is still stale, returns false if
+// another replica claimed it first
+func (s *JobStorage) ClaimStaleJob(id primitive.ObjectID, now time.Time, owner string, expiresAt time.Time) (bool, error) {
+	collection := s.db.Collection(JOB_COLLECTION)
+
+	filter := staleJobFilter(now)
+	filter["_id"] = id
+	update := bson.M{"$set": bson.M{"owner": owner, "leaseExpiresAt": expiresAt}}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func staleJobFilter(now time.Time) bson.M {
+	return bson.M{
+		"status":         bson.M{"$in": bson.A{JobQueued, JobRunning}},
+		"leaseExpiresAt": bson.M{"$not": bson.M{"$gt": now}},
+	}
+}
+
+type jobRunner interface {
+	Run(ctx context.Context, opts RunOptions) (*SyncRunDB, error)
+}
+
+type jobStore interface {
+	CreateJob(job *JobDB) (string, error)
+	FindJobByID(id string) (*JobDB, error)
+	UpdateJob(id primitive.ObjectID, fields bson.M) (*JobDB, error)
+	RequestCancel(id primitive.ObjectID) error
+	RenewJobLeases(owner string, expiresAt time.Time) error
+	FindStaleJobs(now time.Time) ([]JobDB, error)
+	ClaimStaleJob(id primitive.ObjectID, now time.Time, owner string, expiresAt time.Time) (bool, error)
+}
+
+// JobPool runs catalog sync jobs on a fixed number of background goroutines.
+// Jobs wait for the catalog sync lock so they never overlap a scheduled sync
+type JobPool struct {
+	runner  jobRunner
+	storage jobStore
+	newLock func(owner string) locker
+	owner   string
+	size    int
+	queue   chan primitive.ObjectID
+	// The catalog year of jobs that don't request one, i.e CATALOG_YEAR
+	catalogYear string
+
+	mu      sync.Mutex
+	cancels map[primitive.ObjectID]context.CancelFunc
+}
+
+// How often a queued job retries the catalog sync lock
+var jobLockRetryInterval = 5 * time.Second
+
+func NewJobPool(db *mongo.Database, worker *CourseDataWorker, storage *JobStorage, size int, queueSize int, catalogYear string) *JobPool {
+	hostname, _ := os.Hostname()
+
+	return &JobPool{
+		runner:  worker,
+		storage: storage,
+		newLock: func(owner string) locker {
+			return NewLeaseLock(db, CatalogSyncLockName, owner, CatalogSyncLockTTL)
+		},
+		owner:       hostname + "-" + primitive.NewObjectID().Hex(),
+		size:        size,
+		queue:       make(chan primitive.ObjectID, queueSize),
+		cancels:     make(map[primitive.ObjectID]context.CancelFunc),
+		catalogYear: catalogYear,
+	}
+}
+
+// Start runs the pool goroutines until ctx is done, running jobs are canceled.
+// The leases of the jobs of the pool are renewed and stale jobs left behind by
+// a restarted replica are taken over, i.e on startup
+func (p *JobPool) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(lockRenewInterval)
+		defer ticker.Stop()
+		for {
+			p.recoverStaleJobs()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := p.storage.RenewJobLeases(p.owner, time.Now().Add(jobLeaseTTL))
+				if err != nil {
+					fmt.Println("Error renewing catalog sync jobs:", err)
+				}
+			}
+		}
+	}()
+
+	for i := 0; i < p.size; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-p.queue:
+					p.process(ctx, id)
+				}
+			}
+		}()
+	}
+}
+
+// Enqueue stores a queued job and hands it to the pool, an empty catalog year
+// defaults to the catalog year of the pool
+func (p *JobPool) Enqueue(dryRun bool, force bool, catalogYear string) (*JobDB, error) {
+	if catalogYear == "" {
+		catalogYear = p.catalogYear
+	}
+	now := time.Now()
+	leaseExpiresAt := now.Add(jobLeaseTTL)
+	job := JobDB{
+		Status:         JobQueued,
+		DryRun:         dryRun,
+		Force:          force,
+		CatalogYear:    catalogYear,
+		CreatedAt:      now,
+		Owner:          p.owner,
+		LeaseExpiresAt: &leaseExpiresAt,
+	}
+	id, err := p.storage.CreateJob(&job)
+	if err != nil {
+		return nil, err
+	}
+	job.ID, _ = primitive.ObjectIDFromHex(id)
+
+	if !p.push(job.ID) {
+		return nil, ErrJobQueueFull
+	}
+
+	return &job, nil
+}
+
+// push hands the job to the pool, the job fails if the queue is full
+func (p *JobPool) push(id primitive.ObjectID) bool {
+	select {
+	case p.queue <- id:
+		return true
+	default:
+		p.finish(id, JobFailed, ErrJobQueueFull, nil)
+		return false
+	}
+}
+
+// recoverStaleJobs takes over the jobs of replicas that stopped renewing them.
+// Queued jobs are queued again, running jobs failed since their sync stopped
+// halfway and may be retried by an admin
+func (p *JobPool) recoverStaleJobs() {
+	now := time.Now()
+	jobs, err := p.storage.FindStaleJobs(now)
+	if err != nil {
+		fmt.Println("Error fetching stale catalog sync jobs:", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if job.Owner == p.owner {
+			// Still queued or running here, the lease is renewed on the next tick
+			continue
+		}
+		claimed, err := p.storage.ClaimStaleJob(job.ID, now, p.owner, now.Add(jobLeaseTTL))
+		if err != nil {
+			fmt.Println("Error claiming stale catalog sync job:", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if job.Status == JobRunning {
+			p.finish(job.ID, JobFailed, ErrJobAbandoned, nil)
+			continue
+		}
+		p.push(job.ID)
+	}
+}
+
+// Cancel cancels the job if it runs on this replica and flags it for the
+// replica running it otherwise
+func (p *JobPool) Cancel(id string) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrJobNotFound
+	}
+
+	err = p.storage.RequestCancel(objId)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	cancel, ok := p.cancels[objId]
+	p.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return nil
+}
+
+func (p *JobPool) process(ctx context.Context, id primitive.ObjectID) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p.mu.Lock()
+	p.cancels[id] = cancel
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.cancels, id)
+		p.mu.Unlock()
+	}()
+
+	reporter := &jobProgress{storage: p.storage, id: id, cancel: cancel}
+	job, err := p.storage.FindJobByID(id.Hex())
+	if err != nil {
+		fmt.Println("Error fetching catalog sync job:", err)
+		return
+	}
+	if job.Status != JobQueued || job.Owner != p.owner {
+		// Taken over by another replica while this replica stalled
+		return
+	}
+	if job.CancelRequested {
+		p.finish(id, JobCanceled, context.Canceled, nil)
+		return
+	}
+
+	// Wait for any other sync to finish
+	lock := p.newLock("job-" + id.Hex())
+	for {
+		acquired, err := lock.Acquire(ctx)
+		if err != nil {
+			p.finish(id, JobFailed, fmt.Errorf("acquiring catalog sync lock: %w", err), nil)
+			return
+		}
+		if acquired {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			p.finish(id, JobCanceled, ctx.Err(), nil)
+			return
+		case <-time.After(jobLockRetryInterval):
+			if reporter.cancelRequested() {
+				p.finish(id, JobCanceled, context.Canceled, nil)
+				return
+			}
+		}
+	}
+	defer lock.Release(context.Background())
+
+	// Keep the lease while the job runs, the job is canceled if it is lost
+	stopLease := keepLease(ctx, lock, cancel)
+
+	now := time.Now()
+	p.storage.UpdateJob(id, bson.M{"status": JobRunning, "startedAt": now})
+
+	run, err := p.runner.Run(ctx, RunOptions{
+		DryRun:      job.DryRun,
+		Force:       job.Force,
+		CatalogYear: job.CatalogYear,
+		Trigger:     SyncTriggerAdmin,
+		Progress:    reporter,
+	})
+	if leaseErr := stopLease(); leaseErr != nil {
+		err = leaseErr
+	}
+
+	status := JobSucceeded
+	if errors.Is(err, context.Canceled) {
+		status = JobCanceled
+	} else if err != nil {
+		status = JobFailed
+	}
+	p.finish(id, status, err, run)
+}
+
+func (p *JobPool) finish(id primitive.ObjectID, status string, err error, run *SyncRunDB) {
+	fields := bson.M{"status": status, "finishedAt": time.Now()}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	if run != nil && !run.ID.IsZero() {
+		fields["syncRunID"] = run.ID
+	}
+	_, updateErr := p.storage.UpdateJob(id, fields)
+	if updateErr != nil {
+		fmt.Println("Error updating catalog sync job:", updateErr)
+	}
+}
+
+// jobProgress stores the stage and progress of a job, a cancel requested on
+// another replica cancels the job at the next stage
+type jobProgress struct {
+	storage jobStore
+	id      primitive.ObjectID
+	cancel  context.CancelFunc
+
+	lastUpdate time.Time
+}
+
+// Minimum time between progress writes within a stage
+const jobProgressInterval = time.Second
+
+func (p *jobProgress) Stage(stage string) {
+	p.lastUpdate = time.Now()
+	job, err := p.storage.UpdateJob(p.id, bson.M{"stage": stage, "progress": JobProgress{}})
+	if err != nil {
+		fmt.Println("Error updating catalog sync job:", err)
+		return
+	}
+	if job.CancelRequested {
+		p.cancel()
+	}
+}
+
+func (p *jobProgress) Progress(done int, total int) {
+	if done < total && time.Since(p.lastUpdate) < jobProgressInterval {
+		return
+	}
+	p.lastUpdate = time.Now()
+	_, err := p.storage.UpdateJob(p.id, bson.M{"progress": JobProgress{Done: done, Total: total}})
+	if err != nil {
+		fmt.Println("Error updating catalog sync job:", err)
+	}
+}
+
+func (p *jobProgress) cancelRequested() bool {
+	job, err := p.storage.FindJobByID(p.id.Hex())
+	return err == nil && job.CancelRequested
+}
@@ -0,0 +1,430 @@
+package workers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeJobStore keeps the jobs in memory, updates go through bson like mongo
+type fakeJobStore struct {
+	mu   sync.Mutex
+	jobs map[primitive.ObjectID]JobDB
+}
+
+func newFakeJobStore() *fakeJobStore {
+	return &fakeJobStore{jobs: make(map[primitive.ObjectID]JobDB)}
+}
+
+func (s *fakeJobStore) CreateJob(job *JobDB) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *job
+	stored.ID = primitive.NewObjectID()
+	s.jobs[stored.ID] = stored
+	return stored.ID.Hex(), nil
+}
+
+func (s *fakeJobStore) FindJobByID(id string) (*JobDB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	job, ok := s.jobs[objId]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &job, nil
+}
+
+func (s *fakeJobStore) UpdateJob(id primitive.ObjectID, fields bson.M) (*JobDB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	// Set the fields on the bson document of the job
+	data, err := bson.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		doc[key] = value
+	}
+	data, err = bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	job = JobDB{}
+	if err := bson.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+
+	s.jobs[id] = job
+	return &job, nil
+}
+
+func (s *fakeJobStore) RequestCancel(id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok || (job.Status != JobQueued && job.Status != JobRunning) {
+		return ErrJobNotCancelable
+	}
+	job.CancelRequested = true
+	s.jobs[id] = job
+	return nil
+}
+
+func (s *fakeJobStore) RenewJobLeases(owner string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, job := range s.jobs {
+		if job.Owner == owner && (job.Status == JobQueued || job.Status == JobRunning) {
+			job.LeaseExpiresAt = &expiresAt
+			s.jobs[id] = job
+		}
+	}
+	return nil
+}
+
+func (s *fakeJobStore) FindStaleJobs(now time.Time) ([]JobDB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := []JobDB{}
+	for _, job := range s.jobs {
+		if fakeJobStale(job, now) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *fakeJobStore) ClaimStaleJob(id primitive.ObjectID, now time.Time, owner string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok || !fakeJobStale(job, now) {
+		return false, nil
+	}
+	job.Owner = owner
+	job.LeaseExpiresAt = &expiresAt
+	s.jobs[id] = job
+	return true, nil
+}
+
+func fakeJobStale(job JobDB, now time.Time) bool {
+	active := job.Status == JobQueued || job.Status == JobRunning
+	return active && (job.LeaseExpiresAt == nil || !job.LeaseExpiresAt.After(now))
+}
+
+func newTestJobPool(runner *fakeRunner, lock *fakeLocker, queueSize int) (*JobPool, *fakeJobStore) {
+	storage := newFakeJobStore()
+	return &JobPool{
+		runner:  runner,
+		storage: storage,
+		newLock: func(owner string) locker { return lock },
+		owner:   "test-pool",
+		size:    1,
+		queue:   make(chan primitive.ObjectID, queueSize),
+		cancels: make(map[primitive.ObjectID]context.CancelFunc),
+	}, storage
+}
+
+// waitForJob waits until the job has the status and returns it
+func waitForJob(t *testing.T, storage *fakeJobStore, id primitive.ObjectID, status string) *JobDB {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := storage.FindJobByID(id.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected job status %v, got %v", status, job.Status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestJobPool__Run(t *testing.T) {
+	runner := &fakeRunner{}
+	lock := &fakeLocker{}
+	pool, storage := newTestJobPool(runner, lock, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	job, err := pool.Enqueue(true, true, "2024-2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+	finished := waitForJob(t, storage, job.ID, JobSucceeded)
+	if finished.StartedAt == nil || finished.FinishedAt == nil || finished.Error != "" {
+		t.Errorf("Expected a finished job without error, got %+v", finished)
+	}
+
+	if runner.runCount() != 1 {
+		t.Fatalf("Expected 1 run, got %v", runner.runCount())
+	}
+	opts := runner.runs[0]
+	if !opts.DryRun || !opts.Force || opts.CatalogYear != "2024-2025" || opts.Trigger != SyncTriggerAdmin {
+		t.Errorf("Expected the job options, got %+v", opts)
+	}
+	if acquired, released := lock.counts(); acquired != 1 || released != 1 {
+		t.Errorf("Expected the lock acquired and released once, got %v and %v", acquired, released)
+	}
+}
+
+func TestJobPool__DefaultCatalogYear(t *testing.T) {
+	runner := &fakeRunner{}
+	pool, storage := newTestJobPool(runner, &fakeLocker{}, 4)
+	pool.catalogYear = "2024-2025"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	tests := []struct {
+		name        string
+		catalogYear string
+		expected    string
+	}{
+		{name: "empty request", catalogYear: "", expected: "2024-2025"},
+		{name: "requested year", catalogYear: "2025-2026", expected: "2025-2026"},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			job, err := pool.Enqueue(false, false, test.catalogYear)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.CatalogYear != test.expected {
+				t.Errorf("Expected the job for %v, got %q", test.expected, job.CatalogYear)
+			}
+			waitForJob(t, storage, job.ID, JobSucceeded)
+			if runner.runCount() != i+1 {
+				t.Fatalf("Expected %v runs, got %v", i+1, runner.runCount())
+			}
+			if year := runner.runs[i].CatalogYear; year != test.expected {
+				t.Errorf("Expected the sync of %v, got %q", test.expected, year)
+			}
+		})
+	}
+}
+
+func TestJobPool__QueueFull(t *testing.T) {
+	pool, storage := newTestJobPool(&fakeRunner{}, &fakeLocker{}, 1)
+
+	// The pool is not started so the first job stays queued
+	if _, err := pool.Enqueue(false, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Enqueue(false, false, ""); err != ErrJobQueueFull {
+		t.Fatalf("Expected %v, got %v", ErrJobQueueFull, err)
+	}
+
+	failed := 0
+	for _, job := range storage.jobs {
+		if job.Status == JobFailed && job.Error == ErrJobQueueFull.Error() {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("Expected the rejected job to be failed, got %+v", storage.jobs)
+	}
+}
+
+func TestJobPool__WaitForLock(t *testing.T) {
+	previous := jobLockRetryInterval
+	jobLockRetryInterval = 5 * time.Millisecond
+	defer func() { jobLockRetryInterval = previous }()
+
+	// A scheduled sync holds the lock
+	runner := &fakeRunner{}
+	lock := &fakeLocker{held: true}
+	pool, storage := newTestJobPool(runner, lock, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	job, err := pool.Enqueue(false, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if waiting := waitForJob(t, storage, job.ID, JobQueued); waiting.StartedAt != nil || runner.runCount() != 0 {
+		t.Fatalf("Expected the job to wait for the lock, got %+v", waiting)
+	}
+
+	lock.setHeld(false)
+	waitForJob(t, storage, job.ID, JobSucceeded)
+	if runner.runCount() != 1 {
+		t.Errorf("Expected 1 run, got %v", runner.runCount())
+	}
+}
+
+func TestJobPool__Cancel(t *testing.T) {
+	previous := jobLockRetryInterval
+	jobLockRetryInterval = 5 * time.Millisecond
+	defer func() { jobLockRetryInterval = previous }()
+
+	t.Run("waiting for the lock", func(t *testing.T) {
+		runner := &fakeRunner{}
+		pool, storage := newTestJobPool(runner, &fakeLocker{held: true}, 4)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		pool.Start(ctx)
+
+		job, err := pool.Enqueue(false, false, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pool.Cancel(job.ID.Hex()); err != nil {
+			t.Fatal(err)
+		}
+		waitForJob(t, storage, job.ID, JobCanceled)
+		if runner.runCount() != 0 {
+			t.Errorf("Expected no run, got %v", runner.runCount())
+		}
+	})
+
+	t.Run("running", func(t *testing.T) {
+		runner := &fakeRunner{block: make(chan struct{})}
+		lock := &fakeLocker{}
+		pool, storage := newTestJobPool(runner, lock, 4)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		pool.Start(ctx)
+
+		job, err := pool.Enqueue(false, false, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForJob(t, storage, job.ID, JobRunning)
+		if err := pool.Cancel(job.ID.Hex()); err != nil {
+			t.Fatal(err)
+		}
+		waitForJob(t, storage, job.ID, JobCanceled)
+		if _, released := lock.counts(); released != 1 {
+			t.Errorf("Expected the lock to be released, got %v releases", released)
+		}
+
+		// Finished jobs can't be canceled
+		if err := pool.Cancel(job.ID.Hex()); err != ErrJobNotCancelable {
+			t.Errorf("Expected %v, got %v", ErrJobNotCancelable, err)
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		pool, _ := newTestJobPool(&fakeRunner{}, &fakeLocker{}, 4)
+		if err := pool.Cancel("not-an-id"); err != ErrJobNotFound {
+			t.Errorf("Expected %v, got %v", ErrJobNotFound, err)
+		}
+	})
+}
+
+func TestJobPool__LostLease(t *testing.T) {
+	withLockRenewInterval(t, 10*time.Millisecond)
+
+	// Another owner takes the lock when the lease is renewed
+	runner := &fakeRunner{block: make(chan struct{})}
+	pool, storage := newTestJobPool(runner, &fakeLocker{loseAfter: 1}, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	job, err := pool.Enqueue(false, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := waitForJob(t, storage, job.ID, JobFailed)
+	if !strings.Contains(failed.Error, ErrLockLost.Error()) {
+		t.Errorf("Expected a lost lock error, got %q", failed.Error)
+	}
+}
+
+func TestJobPool__RecoverStaleJobs(t *testing.T) {
+	runner := &fakeRunner{}
+	pool, storage := newTestJobPool(runner, &fakeLocker{}, 4)
+
+	// Jobs left behind by a restarted replica and a job of a live replica
+	expired := time.Now().Add(-time.Minute)
+	live := time.Now().Add(time.Minute)
+	create := func(job JobDB) primitive.ObjectID {
+		id, err := storage.CreateJob(&job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objId, _ := primitive.ObjectIDFromHex(id)
+		return objId
+	}
+	running := create(JobDB{Status: JobRunning, Owner: "restarted", LeaseExpiresAt: &expired})
+	queued := create(JobDB{Status: JobQueued, Owner: "restarted", LeaseExpiresAt: &expired})
+	unleased := create(JobDB{Status: JobQueued})
+	otherReplica := create(JobDB{Status: JobQueued, Owner: "live", LeaseExpiresAt: &live})
+	finished := create(JobDB{Status: JobSucceeded, Owner: "restarted", LeaseExpiresAt: &expired})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	abandoned := waitForJob(t, storage, running, JobFailed)
+	if abandoned.Error != ErrJobAbandoned.Error() || abandoned.Owner != pool.owner {
+		t.Errorf("Expected the running job to be abandoned, got %+v", abandoned)
+	}
+	waitForJob(t, storage, queued, JobSucceeded)
+	waitForJob(t, storage, unleased, JobSucceeded)
+	if runner.runCount() != 2 {
+		t.Errorf("Expected the queued jobs to run again, got %v runs", runner.runCount())
+	}
+
+	if job, _ := storage.FindJobByID(otherReplica.Hex()); job.Status != JobQueued || job.Owner != "live" {
+		t.Errorf("Expected the job of the live replica untouched, got %+v", job)
+	}
+	if job, _ := storage.FindJobByID(finished.Hex()); job.Owner != "restarted" {
+		t.Errorf("Expected the finished job untouched, got %+v", job)
+	}
+}
+
+func TestJobPool__RenewJobLeases(t *testing.T) {
+	withLockRenewInterval(t, 10*time.Millisecond)
+
+	// The job waits for the lock while its lease is renewed
+	pool, storage := newTestJobPool(&fakeRunner{}, &fakeLocker{held: true}, 4)
+	job, err := pool.Enqueue(false, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := time.Now().Add(-time.Second)
+	storage.UpdateJob(job.ID, bson.M{"leaseExpiresAt": expired})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		renewed, _ := storage.FindJobByID(job.ID.Hex())
+		if renewed.LeaseExpiresAt.After(time.Now()) && renewed.Owner == pool.owner {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the lease to be renewed, got %+v", renewed)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
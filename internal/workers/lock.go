@@ -0,0 +1,154 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	LOCK_COLLECTION = "worker_locks"
+)
+
+var (
+	ErrLockLost = errors.New("catalog sync lock lost")
+	ErrLockHeld = errors.New("catalog sync lock held by another owner")
+)
+
+// How often a held lease is renewed, well within the lease ttl
+var lockRenewInterval = CatalogSyncLockTTL / 3
+
+// How a lock looks in MongoDB, the lock is free once the lease expired
+type LockDB struct {
+	Name      string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// LeaseLock is a lock shared by every replica through MongoDB. The lease
+// expires after ttl so a crashed owner does not hold the lock forever
+type LeaseLock struct {
+	db    *mongo.Database
+	name  string
+	owner string
+	ttl   time.Duration
+}
+
+func NewLeaseLock(db *mongo.Database, name string, owner string, ttl time.Duration) *LeaseLock {
+	return &LeaseLock{
+		db:    db,
+		name:  name,
+		owner: owner,
+		ttl:   ttl,
+	}
+}
+
+// Acquire takes the lock if it is free or extends the lease if it is already
+// held by this owner. Returns false if another owner holds the lock
+func (l *LeaseLock) Acquire(ctx context.Context) (bool, error) {
+	collection := l.db.Collection(LOCK_COLLECTION)
+
+	now := time.Now()
+	filter := bson.M{
+		"_id": l.name,
+		"$or": bson.A{
+			bson.M{"owner": l.owner},
+			bson.M{"expiresAt": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"owner":     l.owner,
+		"expiresAt": now.Add(l.ttl),
+	}}
+
+	// The upsert conflicts on _id if another owner holds an unexpired lease
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Release frees the lock if it is held by this owner
+func (l *LeaseLock) Release(ctx context.Context) error {
+	collection := l.db.Collection(LOCK_COLLECTION)
+
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": l.name, "owner": l.owner})
+	return err
+}
+
+// keepLease renews the lease of the held lock until stop is called. If the
+// lease is lost, i.e it expired while mongo was slow and another owner took
+// the lock, cancel is called so the holder stops writing. stop returns
+// ErrLockLost if the lease was lost
+func keepLease(ctx context.Context, lock locker, cancel context.CancelFunc) (stop func() error) {
+	done := make(chan struct{})
+	finished := make(chan error, 1)
+	go func() {
+		ticker := time.NewTicker(lockRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				finished <- nil
+				return
+			case <-ticker.C:
+				acquired, err := lock.Acquire(ctx)
+				if ctx.Err() != nil {
+					// Canceled by the holder, wait for stop
+					continue
+				}
+				if err != nil {
+					err = fmt.Errorf("%w: %v", ErrLockLost, err)
+				} else if !acquired {
+					err = ErrLockLost
+				}
+				if err != nil {
+					cancel()
+					<-done
+					finished <- err
+					return
+				}
+			}
+		}
+	}()
+
+	return func() error {
+		close(done)
+		return <-finished
+	}
+}
+
+// WithLock runs fn while holding the lock, the lease is renewed until fn
+// returns and the context of fn is canceled if the lease is lost. Returns
+// ErrLockHeld if another owner holds the lock and ErrLockLost if the lease
+// was lost while fn ran
+func WithLock(ctx context.Context, lock locker, fn func(ctx context.Context) error) error {
+	acquired, err := lock.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring catalog sync lock: %w", err)
+	}
+	if !acquired {
+		return ErrLockHeld
+	}
+	// Release with a fresh context so the lock is freed on cancel
+	defer lock.Release(context.Background())
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopLease := keepLease(ctx, lock, cancel)
+
+	err = fn(ctx)
+	if leaseErr := stopLease(); leaseErr != nil {
+		return leaseErr
+	}
+	return err
+}
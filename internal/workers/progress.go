@@ -0,0 +1,21 @@
+package workers
+
+// Stages of a course data worker run
+const (
+	SyncStageFetching     = "fetching"
+	SyncStageParsing      = "parsing"
+	SyncStageCrossListing = "cross_listing_resolution"
+	SyncStageWriting      = "writing"
+)
+
+// A ProgressReporter is notified as a run moves through its stages, progress
+// counts the items of the current stage i.e written courses
+type ProgressReporter interface {
+	Stage(stage string)
+	Progress(done int, total int)
+}
+
+type nopProgress struct{}
+
+func (nopProgress) Stage(stage string)           {}
+func (nopProgress) Progress(done int, total int) {}
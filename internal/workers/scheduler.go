@@ -0,0 +1,150 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	CatalogSyncLockName = "catalog_sync"
+	// The lease is renewed while a sync runs, it only expires if the owner dies
+	CatalogSyncLockTTL = 2 * time.Minute
+)
+
+// ParseSchedule parses an interval i.e 6h or a standard cron expression i.e
+// "0 3 * * *"
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	if interval, err := time.ParseDuration(spec); err == nil {
+		if interval < time.Second {
+			return nil, errors.New("sync interval must be at least 1s")
+		}
+		return cron.Every(interval), nil
+	}
+	return cron.ParseStandard(spec)
+}
+
+type syncRunner interface {
+	Run(ctx context.Context, opts RunOptions) (*SyncRunDB, error)
+}
+
+type locker interface {
+	Acquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// Scheduler runs the course data worker in process on a schedule, the lease
+// lock makes sure only one replica syncs at a time
+type Scheduler struct {
+	runner   syncRunner
+	lock     locker
+	spec     string
+	schedule cron.Schedule
+	opts     RunOptions
+
+	mu      sync.Mutex
+	nextRun time.Time
+	lastRun *SyncRunDB
+}
+
+// The state of the scheduler on this replica
+type SchedulerStatus struct {
+	Schedule string     `json:"schedule"`
+	NextRun  time.Time  `json:"nextRun"`
+	LastRun  *SyncRunDB `json:"lastRun,omitempty"`
+}
+
+func NewScheduler(db *mongo.Database, worker *CourseDataWorker, spec string, opts RunOptions) (*Scheduler, error) {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid catalog sync schedule %q: %w", spec, err)
+	}
+
+	hostname, _ := os.Hostname()
+	owner := hostname + "-" + primitive.NewObjectID().Hex()
+	opts.Trigger = SyncTriggerScheduler
+
+	return &Scheduler{
+		runner:   worker,
+		lock:     NewLeaseLock(db, CatalogSyncLockName, owner, CatalogSyncLockTTL),
+		spec:     spec,
+		schedule: schedule,
+		opts:     opts,
+	}, nil
+}
+
+// Start runs the scheduler in the background until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		for {
+			next := s.schedule.Next(time.Now())
+			s.mu.Lock()
+			s.nextRun = next
+			s.mu.Unlock()
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			s.runOnce(ctx)
+		}
+	}()
+}
+
+func (s *Scheduler) Status() SchedulerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return SchedulerStatus{
+		Schedule: s.spec,
+		NextRun:  s.nextRun,
+		LastRun:  s.lastRun,
+	}
+}
+
+// runOnce syncs the catalog unless another replica holds the lock, the lease
+// is renewed until the sync finishes and the sync is canceled if the lease is
+// lost. Returns whether the sync ran
+func (s *Scheduler) runOnce(ctx context.Context) bool {
+	acquired, err := s.lock.Acquire(ctx)
+	if err != nil {
+		fmt.Println("Error acquiring catalog sync lock:", err)
+		return false
+	}
+	if !acquired {
+		fmt.Println("Catalog sync lock held by another replica: skipping sync")
+		return false
+	}
+
+	// Stop the sync if the lease is lost so it never overlaps another replica
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopLease := keepLease(ctx, s.lock, cancel)
+
+	run, _ := s.runner.Run(ctx, s.opts)
+	if err := stopLease(); err != nil {
+		fmt.Println("Error renewing catalog sync lock:", err)
+	}
+
+	s.mu.Lock()
+	s.lastRun = run
+	s.mu.Unlock()
+
+	// Release with a fresh context so the lock is freed on shutdown
+	err = s.lock.Release(context.Background())
+	if err != nil {
+		fmt.Println("Error releasing catalog sync lock:", err)
+	}
+	return true
+}
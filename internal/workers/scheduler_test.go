@@ -0,0 +1,204 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2024, 9, 2, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"6h", now.Add(6 * time.Hour)},
+		{"0 3 * * *", time.Date(2024, 9, 3, 3, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 9, 2, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		schedule, err := ParseSchedule(test.spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.spec, err)
+			continue
+		}
+		if next := schedule.Next(now); !next.Equal(test.expected) {
+			t.Errorf("%q: expected next run %v, got %v", test.spec, test.expected, next)
+		}
+	}
+
+	for _, spec := range []string{"", "10ms", "every day", "0 3 * *"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+type fakeLocker struct {
+	mu       sync.Mutex
+	held     bool
+	acquired int
+	released int
+	// Lose the lease once it was acquired this many times, 0 keeps it
+	loseAfter int
+}
+
+func (l *fakeLocker) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held || (l.loseAfter > 0 && l.acquired >= l.loseAfter) {
+		return false, nil
+	}
+	l.acquired++
+	return true, nil
+}
+
+func (l *fakeLocker) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released++
+	return nil
+}
+
+func (l *fakeLocker) setHeld(held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = held
+}
+
+func (l *fakeLocker) counts() (int, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.acquired, l.released
+}
+
+type fakeRunner struct {
+	mu   sync.Mutex
+	runs []RunOptions
+	// Runs block until unblocked or canceled if set
+	block chan struct{}
+}
+
+func (r *fakeRunner) Run(ctx context.Context, opts RunOptions) (*SyncRunDB, error) {
+	r.mu.Lock()
+	r.runs = append(r.runs, opts)
+	r.mu.Unlock()
+	if r.block != nil {
+		select {
+		case <-r.block:
+		case <-ctx.Done():
+			return &SyncRunDB{Status: SyncRunCanceled, Trigger: opts.Trigger}, ctx.Err()
+		}
+	}
+	return &SyncRunDB{Status: SyncRunSucceeded, Trigger: opts.Trigger}, nil
+}
+
+func (r *fakeRunner) runCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.runs)
+}
+
+// withLockRenewInterval shortens the lease renewal for the test
+func withLockRenewInterval(t *testing.T, interval time.Duration) {
+	previous := lockRenewInterval
+	lockRenewInterval = interval
+	t.Cleanup(func() { lockRenewInterval = previous })
+}
+
+func TestScheduler__RunOnce(t *testing.T) {
+	lock := &fakeLocker{}
+	runner := &fakeRunner{}
+	scheduler := &Scheduler{runner: runner, lock: lock, opts: RunOptions{Trigger: SyncTriggerScheduler}}
+
+	if !scheduler.runOnce(context.Background()) {
+		t.Fatal("Expected the sync to run")
+	}
+	if len(runner.runs) != 1 || lock.acquired != 1 || lock.released != 1 {
+		t.Errorf("Expected 1 run with the lock acquired and released, got %v runs, %v acquired and %v released", len(runner.runs), lock.acquired, lock.released)
+	}
+	if last := scheduler.Status().LastRun; last == nil || last.Trigger != SyncTriggerScheduler {
+		t.Errorf("Expected the last run to be recorded, got %+v", last)
+	}
+
+	// Another replica holds the lock
+	lock.held = true
+	if scheduler.runOnce(context.Background()) {
+		t.Error("Expected the sync to be skipped")
+	}
+	if len(runner.runs) != 1 {
+		t.Errorf("Expected no additional run, got %v runs", len(runner.runs))
+	}
+}
+
+func TestScheduler__LostLease(t *testing.T) {
+	withLockRenewInterval(t, 10*time.Millisecond)
+
+	// Another replica takes the lock when the lease is renewed
+	lock := &fakeLocker{loseAfter: 1}
+	runner := &fakeRunner{block: make(chan struct{})}
+	scheduler := &Scheduler{runner: runner, lock: lock, opts: RunOptions{Trigger: SyncTriggerScheduler}}
+
+	ran := make(chan bool)
+	go func() {
+		ran <- scheduler.runOnce(context.Background())
+	}()
+
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the sync to be canceled when the lease is lost")
+	}
+	if last := scheduler.Status().LastRun; last == nil || last.Status != SyncRunCanceled {
+		t.Errorf("Expected a canceled run, got %+v", last)
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	withLockRenewInterval(t, 10*time.Millisecond)
+
+	t.Run("held", func(t *testing.T) {
+		lock := &fakeLocker{held: true}
+		ran := false
+		err := WithLock(context.Background(), lock, func(ctx context.Context) error {
+			ran = true
+			return nil
+		})
+		if !errors.Is(err, ErrLockHeld) || ran {
+			t.Errorf("Expected ErrLockHeld without running, got %v and ran %v", err, ran)
+		}
+	})
+
+	t.Run("released", func(t *testing.T) {
+		lock := &fakeLocker{}
+		failed := errors.New("sync failed")
+		err := WithLock(context.Background(), lock, func(ctx context.Context) error {
+			return failed
+		})
+		if !errors.Is(err, failed) {
+			t.Errorf("Expected the error of fn, got %v", err)
+		}
+		if acquired, released := lock.counts(); acquired != 1 || released != 1 {
+			t.Errorf("Expected the lock acquired and released once, got %v and %v", acquired, released)
+		}
+	})
+
+	t.Run("lost lease", func(t *testing.T) {
+		lock := &fakeLocker{loseAfter: 1}
+		err := WithLock(context.Background(), lock, func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		})
+		if !errors.Is(err, ErrLockLost) {
+			t.Errorf("Expected ErrLockLost, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/huynchu/degree-planner-api/config"
+)
+
+// Datasets a catalog source provides
+const (
+	DatasetCatalog     = "catalog"
+	DatasetPrereqs     = "prereqs"
+	DatasetCourseList  = "course_list"
+	DatasetCatalogYAML = "catalog_yaml"
+)
+
+const (
+	CatalogSourceFile = "file"
+	CatalogSourceHTTP = "http"
+	CatalogSourceS3   = "s3"
+)
+
+var ErrUnknownDataset = errors.New("unknown catalog dataset")
+
+// File name of every dataset in a data directory or s3 bucket
+var datasetFiles = map[string]string{
+	DatasetCatalog:     "catalog.json",
+	DatasetPrereqs:     "prereq_data.json",
+	DatasetCourseList:  "courses.csv",
+	DatasetCatalogYAML: "catalog.yaml",
+}
+
+// A CatalogSource provides the raw course data the worker ingests
+type CatalogSource interface {
+	Fetch(ctx context.Context, dataset string) ([]byte, error)
+}
+
+// A ConditionalSource only downloads a dataset that changed since the version
+// of the last run, returning nil data and the same version otherwise
+type ConditionalSource interface {
+	CatalogSource
+	FetchIfChanged(ctx context.Context, dataset string, since SourceVersion) ([]byte, SourceVersion, error)
+}
+
+// NewCatalogSource creates the catalog source selected by CATALOG_SOURCE,
+// defaulting to the local data directory outside of production
+func NewCatalogSource(ctx context.Context, env config.EnvVars) (CatalogSource, error) {
+	source := env.CATALOG_SOURCE
+	if source == "" {
+		source = CatalogSourceFile
+		if env.GO_ENV == "prod" || env.GO_ENV == "production" {
+			source = CatalogSourceHTTP
+		}
+	}
+
+	switch source {
+	case CatalogSourceFile:
+		dir := env.CATALOG_DATA_DIR
+		if dir == "" {
+			dir = filepath.Join("internal", "data")
+		}
+		return NewFileSource(dir), nil
+	case CatalogSourceHTTP:
+		// single file formats are served from the course data url
+		return NewHTTPSource(http.DefaultClient, map[string]string{
+			DatasetCatalog:     env.COURSE_DATA_URL,
+			DatasetPrereqs:     env.COURSE_PREREQ_DATA_URL,
+			DatasetCourseList:  env.COURSE_DATA_URL,
+			DatasetCatalogYAML: env.COURSE_DATA_URL,
+		}), nil
+	case CatalogSourceS3:
+		if env.CATALOG_S3_BUCKET == "" {
+			return nil, errors.New("CATALOG_S3_BUCKET is required for the s3 catalog source")
+		}
+		cfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return NewS3Source(s3.NewFromConfig(cfg), env.CATALOG_S3_BUCKET, env.CATALOG_S3_PREFIX), nil
+	default:
+		return nil, fmt.Errorf("invalid CATALOG_SOURCE %q: must be file, http or s3", source)
+	}
+}
+
+// FileSource reads every dataset from its file in a local directory
+type FileSource struct {
+	dir string
+}
+
+func NewFileSource(dir string) *FileSource {
+	return &FileSource{dir: dir}
+}
+
+func (s *FileSource) Fetch(ctx context.Context, dataset string) ([]byte, error) {
+	name, ok := datasetFiles[dataset]
+	if !ok {
+		return nil, ErrUnknownDataset
+	}
+	return os.ReadFile(filepath.Join(s.dir, name))
+}
+
+// HTTPSource downloads every dataset from its url, i.e the quatalog github data
+type HTTPSource struct {
+	client *http.Client
+	urls   map[string]string
+}
+
+func NewHTTPSource(client *http.Client, urls map[string]string) *HTTPSource {
+	return &HTTPSource{client: client, urls: urls}
+}
+
+func (s *HTTPSource) Fetch(ctx context.Context, dataset string) ([]byte, error) {
+	data, _, err := s.FetchIfChanged(ctx, dataset, SourceVersion{})
+	return data, err
+}
+
+// FetchIfChanged sends a conditional request with the ETag and Last-Modified
+// headers of the version, a 304 response means the dataset did not change
+func (s *HTTPSource) FetchIfChanged(ctx context.Context, dataset string, since SourceVersion) ([]byte, SourceVersion, error) {
+	url, ok := s.urls[dataset]
+	if !ok {
+		return nil, since, ErrUnknownDataset
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, since, err
+	}
+	if since.ETag != "" {
+		req.Header.Set("If-None-Match", since.ETag)
+	}
+	if since.LastModified != "" {
+		req.Header.Set("If-Modified-Since", since.LastModified)
+	}
+	response, err := s.client.Do(req)
+	if err != nil {
+		return nil, since, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified {
+		return nil, since, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, since, fmt.Errorf("fetching %s: unexpected status %s", url, response.Status)
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, since, err
+	}
+
+	return data, SourceVersion{
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+		Hash:         hashData(data),
+	}, nil
+}
+
+// S3Source reads every dataset from its object in an s3 bucket
+type S3Source struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func NewS3Source(client *s3.Client, bucket string, prefix string) *S3Source {
+	return &S3Source{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *S3Source) Fetch(ctx context.Context, dataset string) ([]byte, error) {
+	name, ok := datasetFiles[dataset]
+	if !ok {
+		return nil, ErrUnknownDataset
+	}
+
+	key := name
+	if s.prefix != "" {
+		key = strings.TrimSuffix(s.prefix, "/") + "/" + name
+	}
+	object, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer object.Body.Close()
+
+	return io.ReadAll(object.Body)
+}
+
+// MemorySource serves datasets held in memory, i.e for tests
+type MemorySource struct {
+	data map[string][]byte
+}
+
+func NewMemorySource(data map[string][]byte) *MemorySource {
+	return &MemorySource{data: data}
+}
+
+func (s *MemorySource) Fetch(ctx context.Context, dataset string) ([]byte, error) {
+	data, ok := s.data[dataset]
+	if !ok {
+		return nil, ErrUnknownDataset
+	}
+	return data, nil
+}
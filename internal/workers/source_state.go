@@ -0,0 +1,182 @@
+package workers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	SOURCE_STATE_COLLECTION = "catalog_source_states"
+)
+
+// Version of the course documents the worker writes. Bump it whenever the
+// worker writes new fields or derives fields differently, so the next run
+// rewrites the courses even if the source data did not change. Version 2 adds
+// the subject, level, description and attributes of every course
+const catalogSchemaVersion = 2
+
+// The version of a dataset fetched from a catalog source, ETag and
+// Last-Modified are only known for http sources
+type SourceVersion struct {
+	ETag         string `bson:"etag,omitempty" json:"etag,omitempty"`
+	LastModified string `bson:"lastModified,omitempty" json:"lastModified,omitempty"`
+	Hash         string `bson:"hash" json:"hash"`
+	// Schema version and course overrides hash of the run that ingested the dataset
+	Schema    int    `bson:"schema,omitempty" json:"schema,omitempty"`
+	Overrides string `bson:"overrides,omitempty" json:"overrides,omitempty"`
+}
+
+// The version of a dataset ingested by the last successful run of a catalog year
+type SourceStateDB struct {
+	CatalogYear string        `bson:"catalogYear"`
+	Dataset     string        `bson:"dataset"`
+	Version     SourceVersion `bson:"version"`
+	UpdatedAt   time.Time     `bson:"updatedAt"`
+}
+
+type SourceStateStorage struct {
+	db *mongo.Database
+}
+
+func NewSourceStateStorage(db *mongo.Database) *SourceStateStorage {
+	return &SourceStateStorage{
+		db: db,
+	}
+}
+
+// FindSourceStates returns the version of every dataset of the catalog year
+// keyed by dataset
+func (s *SourceStateStorage) FindSourceStates(catalogYear string) (map[string]SourceVersion, error) {
+	collection := s.db.Collection(SOURCE_STATE_COLLECTION)
+
+	cursor, err := collection.Find(context.Background(), bson.M{"catalogYear": catalogYear})
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	var states []SourceStateDB
+	err = cursor.All(context.Background(), &states)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]SourceVersion, len(states))
+	for _, state := range states {
+		versions[state.Dataset] = state.Version
+	}
+	return versions, nil
+}
+
+// UpdateSourceStates upserts the version of every dataset of the catalog year
+func (s *SourceStateStorage) UpdateSourceStates(catalogYear string, versions map[string]SourceVersion) error {
+	collection := s.db.Collection(SOURCE_STATE_COLLECTION)
+
+	now := time.Now()
+	models := []mongo.WriteModel{}
+	for dataset, version := range versions {
+		filter := bson.M{"catalogYear": catalogYear, "dataset": dataset}
+		update := bson.M{"$set": SourceStateDB{
+			CatalogYear: catalogYear,
+			Dataset:     dataset,
+			Version:     version,
+			UpdatedAt:   now,
+		}}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+
+	_, err := collection.BulkWrite(context.Background(), models)
+	return err
+}
+
+// currentSourceVersions returns the versions ingested with the schema version
+// and course overrides, datasets ingested by an older worker or before the
+// overrides were edited have no version so they count as changed
+func currentSourceVersions(versions map[string]SourceVersion, schema int, overrides string) map[string]SourceVersion {
+	res := make(map[string]SourceVersion, len(versions))
+	for dataset, version := range versions {
+		if version.Schema == schema && version.Overrides == overrides {
+			res[dataset] = version
+		}
+	}
+	return res
+}
+
+// stampSourceVersions sets the schema version and course overrides the
+// datasets are ingested with
+func stampSourceVersions(versions map[string]SourceVersion, schema int, overrides string) {
+	for dataset, version := range versions {
+		version.Schema = schema
+		version.Overrides = overrides
+		versions[dataset] = version
+	}
+}
+
+func hashData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// fetchCatalogData fetches the datasets and reports whether any changed since
+// the versions of the last run, a dataset without a version always counts as
+// changed. Datasets a conditional source reports unchanged are only downloaded
+// again if another dataset changed
+func fetchCatalogData(ctx context.Context, source CatalogSource, datasets []string, since map[string]SourceVersion) (map[string][]byte, map[string]SourceVersion, bool, error) {
+	data := make(map[string][]byte, len(datasets))
+	versions := make(map[string]SourceVersion, len(datasets))
+	changed := false
+
+	for _, dataset := range datasets {
+		previous, ok := since[dataset]
+
+		var version SourceVersion
+		conditional, isConditional := source.(ConditionalSource)
+		if isConditional {
+			d, v, err := conditional.FetchIfChanged(ctx, dataset, previous)
+			if err != nil {
+				return nil, nil, false, fmt.Errorf("fetching %s data: %w", dataset, err)
+			}
+			data[dataset], version = d, v
+		} else {
+			d, err := source.Fetch(ctx, dataset)
+			if err != nil {
+				return nil, nil, false, fmt.Errorf("fetching %s data: %w", dataset, err)
+			}
+			data[dataset], version = d, SourceVersion{Hash: hashData(d)}
+		}
+		versions[dataset] = version
+
+		if !ok || (data[dataset] != nil && version.Hash != previous.Hash) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return data, versions, false, nil
+	}
+
+	// Download the datasets that were not modified to load the full catalog
+	for _, dataset := range datasets {
+		if data[dataset] != nil {
+			continue
+		}
+		d, err := source.Fetch(ctx, dataset)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("fetching %s data: %w", dataset, err)
+		}
+		data[dataset] = d
+	}
+
+	return data, versions, true, nil
+}
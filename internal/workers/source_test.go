@@ -0,0 +1,231 @@
+package workers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+)
+
+const testCatalogData = `{
+	"CSCI-1100": {"subj": "CSCI", "csre": "1100", "name": "Computer Science I", "offered": "Fall and spring terms annually.", "credits": 4},
+	"CSCI-1200": {"subj": "CSCI", "csre": "1200", "name": "Data Structures", "credits": "1-4"},
+	"MATH-4100": {"subj": "MATH", "csre": "4100", "name": "Topics in Mathematics"}
+}`
+
+const testPrereqData = `{
+	"CSCI-1200": {"prerequisites": {"type": "course", "course": "CSCI 1100"}},
+	"MATH-4100": {"cross_listings": ["CSCI-4100"]},
+	"CSCI-4100": {"cross_listings": ["MATH-4100"]}
+}`
+
+func TestPopulateCourseData(t *testing.T) {
+	source := NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+		DatasetPrereqs: []byte(testPrereqData),
+	})
+
+	courseData := make(map[string]*course.CourseDB)
+	crossListings, err := populateCourseData(context.Background(), source, courseData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// CSCI-4100 is only known through its cross-listing with MATH-4100
+	if len(courseData) != 4 {
+		t.Errorf("Expected 4 courses, got %v", len(courseData))
+	}
+	if c := courseData["CSCI-1200"]; c == nil || c.PrerequisiteTree.String() != "CSCI-1100" || c.Credits != 4 {
+		t.Errorf("Expected CSCI-1200 with prerequisite CSCI-1100 and 4 credits, got %+v", c)
+	}
+	if groups := crossListingGroups(crossListings); len(groups) != 1 || len(groups[0]) != 2 {
+		t.Errorf("Expected one cross-listing group of 2 courses, got %v", crossListingGroups(crossListings))
+	}
+}
+
+func TestPopulateCourseData__MissingDataset(t *testing.T) {
+	source := NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+	})
+
+	_, err := populateCourseData(context.Background(), source, make(map[string]*course.CourseDB))
+	if err == nil {
+		t.Error("Expected an error for the missing prereq dataset")
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "catalog.json"), []byte(testCatalogData), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewFileSource(dir)
+	data, err := source.Fetch(context.Background(), DatasetCatalog)
+	if err != nil || string(data) != testCatalogData {
+		t.Errorf("Expected catalog data, got %q and %v", data, err)
+	}
+	if _, err := source.Fetch(context.Background(), "unknown"); err != ErrUnknownDataset {
+		t.Errorf("Expected ErrUnknownDataset, got %v", err)
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/catalog.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testCatalogData))
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.Client(), map[string]string{
+		DatasetCatalog: server.URL + "/catalog.json",
+		DatasetPrereqs: server.URL + "/missing.json",
+	})
+
+	data, err := source.Fetch(context.Background(), DatasetCatalog)
+	if err != nil || string(data) != testCatalogData {
+		t.Errorf("Expected catalog data, got %q and %v", data, err)
+	}
+	if _, err := source.Fetch(context.Background(), DatasetPrereqs); err == nil {
+		t.Error("Expected an error for a 404 response")
+	}
+}
+
+func TestHTTPSource__FetchIfChanged(t *testing.T) {
+	const etag = `"v1"`
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 02 Sep 2024 00:00:00 GMT")
+		w.Write([]byte(testCatalogData))
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.Client(), map[string]string{DatasetCatalog: server.URL})
+
+	data, version, err := source.FetchIfChanged(context.Background(), DatasetCatalog, SourceVersion{})
+	if err != nil || string(data) != testCatalogData {
+		t.Fatalf("Expected catalog data, got %q and %v", data, err)
+	}
+	if version.ETag != etag || version.LastModified == "" || version.Hash != hashData(data) {
+		t.Errorf("Expected the response version, got %+v", version)
+	}
+
+	data, unchanged, err := source.FetchIfChanged(context.Background(), DatasetCatalog, version)
+	if err != nil || data != nil || unchanged != version {
+		t.Errorf("Expected not modified with the same version, got %q, %+v and %v", data, unchanged, err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %v", requests)
+	}
+}
+
+func TestFetchCatalogData(t *testing.T) {
+	datasets := []string{DatasetCatalog, DatasetPrereqs}
+	source := NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+		DatasetPrereqs: []byte(testPrereqData),
+	})
+
+	// First run has no versions
+	_, versions, changed, err := fetchCatalogData(context.Background(), source, datasets, nil)
+	if err != nil || !changed {
+		t.Fatalf("Expected changed data on the first run, got %v and %v", changed, err)
+	}
+
+	// Same content hashes as the last run
+	_, _, changed, err = fetchCatalogData(context.Background(), source, datasets, versions)
+	if err != nil || changed {
+		t.Errorf("Expected unchanged data, got %v and %v", changed, err)
+	}
+
+	// One dataset changed
+	source = NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+		DatasetPrereqs: []byte(`{}`),
+	})
+	data, _, changed, err := fetchCatalogData(context.Background(), source, datasets, versions)
+	if err != nil || !changed || len(data) != 2 {
+		t.Errorf("Expected changed data with both datasets, got %v, %v datasets and %v", changed, len(data), err)
+	}
+}
+
+func TestFetchCatalogData__NotModified(t *testing.T) {
+	prereqs := testPrereqData
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := testCatalogData
+		if r.URL.Path == "/prereqs" {
+			body = prereqs
+		}
+		etag := `"` + hashData([]byte(body)) + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	datasets := []string{DatasetCatalog, DatasetPrereqs}
+	source := NewHTTPSource(server.Client(), map[string]string{
+		DatasetCatalog: server.URL + "/catalog",
+		DatasetPrereqs: server.URL + "/prereqs",
+	})
+
+	_, versions, _, err := fetchCatalogData(context.Background(), source, datasets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _, changed, err := fetchCatalogData(context.Background(), source, datasets, versions)
+	if err != nil || changed || data[DatasetCatalog] != nil {
+		t.Errorf("Expected not modified data, got %v and %v", changed, err)
+	}
+
+	// The unmodified catalog is downloaded again when the prereqs change
+	prereqs = `{}`
+	data, _, changed, err = fetchCatalogData(context.Background(), source, datasets, versions)
+	if err != nil || !changed || string(data[DatasetCatalog]) != testCatalogData {
+		t.Errorf("Expected changed data with the full catalog, got %v and %v", changed, err)
+	}
+}
+
+func TestFetchCatalogData__SchemaVersion(t *testing.T) {
+	datasets := []string{DatasetCatalog, DatasetPrereqs}
+	source := NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+		DatasetPrereqs: []byte(testPrereqData),
+	})
+
+	_, versions, _, err := fetchCatalogData(context.Background(), source, datasets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stampSourceVersions(versions, 1, "")
+
+	// Same schema version and data as the last run
+	_, _, changed, err := fetchCatalogData(context.Background(), source, datasets, currentSourceVersions(versions, 1, ""))
+	if err != nil || changed {
+		t.Errorf("Expected unchanged data, got %v and %v", changed, err)
+	}
+
+	// A newer worker ingests the same data again
+	data, _, changed, err := fetchCatalogData(context.Background(), source, datasets, currentSourceVersions(versions, 2, ""))
+	if err != nil || !changed || len(data) != 2 {
+		t.Errorf("Expected changed data after a schema version bump, got %v, %v datasets and %v", changed, len(data), err)
+	}
+}
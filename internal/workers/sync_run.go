@@ -0,0 +1,115 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	SYNC_RUN_COLLECTION = "catalog_sync_runs"
+)
+
+const (
+	SyncRunSucceeded = "succeeded"
+	SyncRunFailed    = "failed"
+	// The catalog source data did not change since the last successful run
+	SyncRunUnchanged = "unchanged"
+	SyncRunCanceled  = "canceled"
+)
+
+// What started a sync run
+const (
+	SyncTriggerCLI       = "cli"
+	SyncTriggerScheduler = "scheduler"
+)
+
+// A single run of the course data worker and the catalog changes it made
+type SyncRunDB struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	StartedAt   time.Time          `bson:"startedAt" json:"startedAt"`
+	FinishedAt  time.Time          `bson:"finishedAt" json:"finishedAt"`
+	Status      string             `bson:"status" json:"status"`
+	DryRun      bool               `bson:"dryRun,omitempty" json:"dryRun"`
+	Trigger     string             `bson:"trigger,omitempty" json:"trigger,omitempty"`
+	CatalogYear string             `bson:"catalogYear,omitempty" json:"catalogYear,omitempty"`
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	CourseCount int                `bson:"courseCount" json:"courseCount"`
+	Diff        CatalogDiff        `bson:"diff" json:"diff"`
+	// Admin course overrides merged over the source data
+	Overrides []AppliedOverride `bson:"overrides,omitempty" json:"overrides"`
+}
+
+// A course override merged over a course during a sync and the fields it
+// replaced, i.e [name prerequisites]
+type AppliedOverride struct {
+	ID     primitive.ObjectID `bson:"id" json:"id"`
+	Code   string             `bson:"code" json:"code"`
+	Fields []string           `bson:"fields" json:"fields"`
+}
+
+type SyncRunStorage struct {
+	db *mongo.Database
+}
+
+func NewSyncRunStorage(db *mongo.Database) *SyncRunStorage {
+	return &SyncRunStorage{
+		db: db,
+	}
+}
+
+func (s *SyncRunStorage) CreateSyncRun(run *SyncRunDB) (string, error) {
+	collection := s.db.Collection(SYNC_RUN_COLLECTION)
+
+	insertResult, err := collection.InsertOne(context.Background(), run)
+	if err != nil {
+		return "", err
+	}
+
+	return insertResult.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// FindSyncRuns returns the most recent sync runs first
+func (s *SyncRunStorage) FindSyncRuns(limit int) ([]SyncRunDB, error) {
+	collection := s.db.Collection(SYNC_RUN_COLLECTION)
+
+	findOptions := options.Find().SetSort(bson.M{"startedAt": -1}).SetLimit(int64(limit))
+	cursor, err := collection.Find(context.Background(), bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	// Decode the results
+	runs := []SyncRunDB{}
+	err = cursor.All(context.Background(), &runs)
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// FindLastSyncedRun returns the latest run that wrote to the courses
+// collection, nil if the catalog was never synced
+func (s *SyncRunStorage) FindLastSyncedRun() (*SyncRunDB, error) {
+	collection := s.db.Collection(SYNC_RUN_COLLECTION)
+
+	filter := bson.M{"status": SyncRunSucceeded, "dryRun": bson.M{"$ne": true}}
+	findOptions := options.FindOne().SetSort(bson.M{"startedAt": -1})
+	var run SyncRunDB
+	err := collection.FindOne(context.Background(), filter, findOptions).Decode(&run)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
@@ -0,0 +1,301 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/huynchu/degree-planner-api/internal/course"
+)
+
+// A single integrity problem found in the catalog data, codes are the course
+// codes involved i.e the missing prerequisites or the courses of a cycle. Line
+// is the line of the source entry, only known for row based formats
+type ValidationIssue struct {
+	Course string   `json:"course"`
+	Codes  []string `json:"codes"`
+	Detail string   `json:"detail"`
+	Line   int      `json:"line,omitempty"`
+}
+
+// The integrity problems of the catalog data by category, every list is
+// sorted by course code
+type ValidationReport struct {
+	CourseCount             int               `json:"courseCount"`
+	MissingPrerequisites    []ValidationIssue `json:"missingPrerequisites"`
+	SelfPrerequisites       []ValidationIssue `json:"selfPrerequisites"`
+	PrerequisiteCycles      []ValidationIssue `json:"prerequisiteCycles"`
+	AsymmetricCrossListings []ValidationIssue `json:"asymmetricCrossListings"`
+	UnparsableCodes         []ValidationIssue `json:"unparsableCodes"`
+	// Source entries that can't be synced, sorted by line
+	MissingFields           []ValidationIssue `json:"missingFields"`
+	DuplicateCodes          []ValidationIssue `json:"duplicateCodes"`
+	UnparsablePrerequisites []ValidationIssue `json:"unparsablePrerequisites"`
+	InvalidClassStandings   []ValidationIssue `json:"invalidClassStandings"`
+}
+
+func (r *ValidationReport) IssueCount() int {
+	return len(r.MissingPrerequisites) + len(r.SelfPrerequisites) + len(r.PrerequisiteCycles) +
+		len(r.AsymmetricCrossListings) + len(r.UnparsableCodes) + len(r.MissingFields) +
+		len(r.DuplicateCodes) + len(r.UnparsablePrerequisites) + len(r.InvalidClassStandings)
+}
+
+// WriteText writes the report grouped by category
+func (r *ValidationReport) WriteText(out io.Writer) {
+	fmt.Fprintln(out, "Validated", r.CourseCount, "courses:", r.IssueCount(), "issues")
+	categories := []struct {
+		name   string
+		issues []ValidationIssue
+	}{
+		{"Missing prerequisites", r.MissingPrerequisites},
+		{"Self prerequisites", r.SelfPrerequisites},
+		{"Prerequisite cycles", r.PrerequisiteCycles},
+		{"Asymmetric cross-listings", r.AsymmetricCrossListings},
+		{"Unparsable codes", r.UnparsableCodes},
+		{"Missing fields", r.MissingFields},
+		{"Duplicate codes", r.DuplicateCodes},
+		{"Unparsable prerequisites", r.UnparsablePrerequisites},
+		{"Invalid class standings", r.InvalidClassStandings},
+	}
+	for _, category := range categories {
+		fmt.Fprintf(out, "%s (%d)\n", category.name, len(category.issues))
+		for _, issue := range category.issues {
+			if issue.Line > 0 {
+				fmt.Fprintf(out, "  line %d %s: %s\n", issue.Line, issue.Course, issue.Detail)
+				continue
+			}
+			fmt.Fprintf(out, "  %s: %s\n", issue.Course, issue.Detail)
+		}
+	}
+}
+
+// Validate fetches and parses the catalog source data and checks its
+// integrity without writing anything. Entries a lenient adapter can't
+// normalize are reported instead of failing the validation
+func (w *CourseDataWorker) Validate(ctx context.Context) (*ValidationReport, error) {
+	datasets := w.adapter.Datasets()
+	data, _, _, err := fetchCatalogData(ctx, w.source, datasets, nil)
+	if err != nil {
+		return nil, err
+	}
+	load := w.adapter.Load
+	if lenient, ok := w.adapter.(LenientAdapter); ok {
+		load = lenient.LoadLenient
+	}
+	catalog, err := load(ctx, NewMemorySource(data))
+	if err != nil {
+		return nil, err
+	}
+	report := validateCatalog(catalog)
+	return &report, nil
+}
+
+func validateCatalog(catalog *Catalog) ValidationReport {
+	report := ValidationReport{
+		CourseCount:             len(catalog.Courses),
+		MissingPrerequisites:    []ValidationIssue{},
+		SelfPrerequisites:       []ValidationIssue{},
+		PrerequisiteCycles:      []ValidationIssue{},
+		AsymmetricCrossListings: []ValidationIssue{},
+		UnparsableCodes:         []ValidationIssue{},
+		MissingFields:           []ValidationIssue{},
+		DuplicateCodes:          []ValidationIssue{},
+		UnparsablePrerequisites: []ValidationIssue{},
+		InvalidClassStandings:   []ValidationIssue{},
+	}
+
+	invalid := map[string]*[]ValidationIssue{
+		InvalidEntryMissingField:  &report.MissingFields,
+		InvalidEntryDuplicateCode: &report.DuplicateCodes,
+		InvalidEntryPrerequisites: &report.UnparsablePrerequisites,
+		InvalidEntryClassStanding: &report.InvalidClassStandings,
+	}
+	for _, entry := range catalog.Invalid {
+		issues := invalid[entry.Reason]
+		codes := []string{}
+		if entry.Code != "" {
+			codes = append(codes, entry.Code)
+		}
+		*issues = append(*issues, ValidationIssue{
+			Course: entry.Code,
+			Codes:  codes,
+			Detail: entry.Err.Error(),
+			Line:   entry.Line,
+		})
+	}
+
+	codes := sortedCodes(catalog.Courses)
+	graph := make(map[string][]string, len(codes))
+	for _, code := range codes {
+		c := catalog.Courses[code]
+		if _, _, ok := course.ParseCode(code); !ok {
+			report.UnparsableCodes = append(report.UnparsableCodes, ValidationIssue{
+				Course: code,
+				Codes:  []string{code},
+				Detail: fmt.Sprintf("course code %q does not parse", code),
+			})
+		}
+
+		prereqs := []string{}
+		if c.PrerequisiteTree != nil {
+			prereqs = uniqueCodes(c.PrerequisiteTree.Courses())
+		}
+		unparsable, missing := []string{}, []string{}
+		for _, prereq := range prereqs {
+			switch {
+			case prereq == code:
+				report.SelfPrerequisites = append(report.SelfPrerequisites, ValidationIssue{
+					Course: code,
+					Codes:  []string{code},
+					Detail: "course is its own prerequisite",
+				})
+			case !parses(prereq):
+				unparsable = append(unparsable, prereq)
+			case catalog.Courses[prereq] == nil:
+				missing = append(missing, prereq)
+			default:
+				graph[code] = append(graph[code], prereq)
+			}
+		}
+		for _, related := range [][]string{c.Corequisites, c.CrossListings} {
+			for _, other := range related {
+				if !parses(other) {
+					unparsable = append(unparsable, other)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			report.MissingPrerequisites = append(report.MissingPrerequisites, ValidationIssue{
+				Course: code,
+				Codes:  missing,
+				Detail: "prerequisites not in the catalog: " + strings.Join(missing, ", "),
+			})
+		}
+		if len(unparsable) > 0 {
+			report.UnparsableCodes = append(report.UnparsableCodes, ValidationIssue{
+				Course: code,
+				Codes:  unparsable,
+				Detail: "referenced codes do not parse: " + strings.Join(unparsable, ", "),
+			})
+		}
+	}
+
+	for _, cycle := range prerequisiteCycles(codes, graph) {
+		report.PrerequisiteCycles = append(report.PrerequisiteCycles, ValidationIssue{
+			Course: cycle[0],
+			Codes:  cycle,
+			Detail: "prerequisite cycle between " + strings.Join(cycle, ", "),
+		})
+	}
+
+	listed := make([]string, 0, len(catalog.CrossListings))
+	for code := range catalog.CrossListings {
+		listed = append(listed, code)
+	}
+	sort.Strings(listed)
+	for _, code := range listed {
+		for _, other := range catalog.CrossListings[code] {
+			if other == code || !parses(other) {
+				continue
+			}
+			if !contains(catalog.CrossListings[other], code) {
+				report.AsymmetricCrossListings = append(report.AsymmetricCrossListings, ValidationIssue{
+					Course: code,
+					Codes:  []string{code, other},
+					Detail: fmt.Sprintf("cross-listed with %s but %s is not cross-listed with %s", other, other, code),
+				})
+			}
+		}
+	}
+
+	return report
+}
+
+// prerequisiteCycles returns the strongly connected components of the
+// prerequisite graph with more than one course using Tarjan's algorithm
+func prerequisiteCycles(codes []string, graph map[string][]string) [][]string {
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := []string{}
+	cycles := [][]string{}
+
+	var visit func(code string)
+	visit = func(code string) {
+		indices[code] = index
+		lowlink[code] = index
+		index++
+		stack = append(stack, code)
+		onStack[code] = true
+
+		for _, prereq := range graph[code] {
+			if _, visited := indices[prereq]; !visited {
+				visit(prereq)
+				if lowlink[prereq] < lowlink[code] {
+					lowlink[code] = lowlink[prereq]
+				}
+			} else if onStack[prereq] && indices[prereq] < lowlink[code] {
+				lowlink[code] = indices[prereq]
+			}
+		}
+
+		if lowlink[code] != indices[code] {
+			return
+		}
+		component := []string{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == code {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, code := range codes {
+		if _, visited := indices[code]; !visited {
+			visit(code)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+func parses(code string) bool {
+	_, _, ok := course.ParseCode(code)
+	return ok
+}
+
+func uniqueCodes(codes []string) []string {
+	seen := make(map[string]bool, len(codes))
+	res := []string{}
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			res = append(res, code)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func contains(codes []string, code string) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
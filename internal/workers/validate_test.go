@@ -0,0 +1,133 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testInvalidPrereqData = `{
+	"CSCI-1100": {"prerequisites": {"type": "course", "course": "CSCI 1200"}},
+	"CSCI-1200": {"prerequisites": {"type": "and", "nested": [
+		{"type": "course", "course": "CSCI 1100"},
+		{"type": "course", "course": "CSCI 9999"},
+		{"type": "course", "course": "CSCI1100"}
+	]}},
+	"MATH-4100": {"prerequisites": {"type": "course", "course": "MATH 4100"}, "cross_listings": ["CSCI-4100"]},
+	"CSCI-4100": {"cross_listings": []}
+}`
+
+func TestValidateCatalog(t *testing.T) {
+	source := NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+		DatasetPrereqs: []byte(testInvalidPrereqData),
+	})
+
+	// A code without a space must not crash the catalog load
+	catalog, err := QuatalogAdapter{}.Load(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := validateCatalog(catalog)
+	if len(report.MissingPrerequisites) != 1 || report.MissingPrerequisites[0].Codes[0] != "CSCI-9999" {
+		t.Errorf("Expected missing prerequisite CSCI-9999, got %+v", report.MissingPrerequisites)
+	}
+	if len(report.SelfPrerequisites) != 1 || report.SelfPrerequisites[0].Course != "MATH-4100" {
+		t.Errorf("Expected MATH-4100 to be its own prerequisite, got %+v", report.SelfPrerequisites)
+	}
+	if len(report.PrerequisiteCycles) != 1 || strings.Join(report.PrerequisiteCycles[0].Codes, ",") != "CSCI-1100,CSCI-1200" {
+		t.Errorf("Expected a cycle between CSCI-1100 and CSCI-1200, got %+v", report.PrerequisiteCycles)
+	}
+	if len(report.AsymmetricCrossListings) != 1 || report.AsymmetricCrossListings[0].Course != "MATH-4100" {
+		t.Errorf("Expected an asymmetric MATH-4100 cross-listing, got %+v", report.AsymmetricCrossListings)
+	}
+	if len(report.UnparsableCodes) != 1 || report.UnparsableCodes[0].Codes[0] != "CSCI1100" {
+		t.Errorf("Expected unparsable code CSCI1100, got %+v", report.UnparsableCodes)
+	}
+	if report.IssueCount() != 5 {
+		t.Errorf("Expected 5 issues, got %v", report.IssueCount())
+	}
+
+	var out bytes.Buffer
+	report.WriteText(&out)
+	if !strings.Contains(out.String(), "Prerequisite cycles (1)") {
+		t.Errorf("Expected the text report to list categories, got %q", out.String())
+	}
+}
+
+func TestValidateCatalog__Valid(t *testing.T) {
+	source := NewMemorySource(map[string][]byte{
+		DatasetCatalog: []byte(testCatalogData),
+		DatasetPrereqs: []byte(testPrereqData),
+	})
+	catalog, err := QuatalogAdapter{}.Load(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report := validateCatalog(catalog); report.IssueCount() != 0 {
+		t.Errorf("Expected no issues, got %+v", report)
+	}
+}
+
+// The fixtures have an entry of every invalid kind, the load fails on the first
+func TestCourseDataWorker__ValidateInvalidEntries(t *testing.T) {
+	tests := []struct {
+		format string
+		lines  []int
+	}{
+		{CatalogFormatCSV, []int{6, 4, 3, 5}},
+		{CatalogFormatYAML, []int{14, 7, 4, 9}},
+	}
+
+	for _, test := range tests {
+		adapter, err := NewCatalogAdapter(test.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		source := NewFileSource(filepath.Join("testdata", "invalid"))
+		if _, err := adapter.Load(context.Background(), source); err == nil || !strings.Contains(err.Error(), "CSCI-1200 prerequisites") {
+			t.Errorf("%s: expected the load to fail on CSCI-1200 prerequisites, got %v", test.format, err)
+		}
+
+		worker := &CourseDataWorker{source: source, adapter: adapter}
+		report, err := worker.Validate(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", test.format, err)
+		}
+
+		categories := []struct {
+			name   string
+			issues []ValidationIssue
+			course string
+		}{
+			{"missing fields", report.MissingFields, "CSCI-4430"},
+			{"duplicate codes", report.DuplicateCodes, "CSCI-1100"},
+			{"unparsable prerequisites", report.UnparsablePrerequisites, "CSCI-1200"},
+			{"invalid class standings", report.InvalidClassStandings, "CSCI-2300"},
+		}
+		for i, category := range categories {
+			if len(category.issues) != 1 || category.issues[0].Course != category.course || category.issues[0].Line != test.lines[i] {
+				t.Errorf("%s: expected %s of %s on line %v, got %+v", test.format, category.name, category.course, test.lines[i], category.issues)
+			}
+		}
+
+		// The valid entries are still checked
+		if report.CourseCount != 3 || len(report.MissingPrerequisites) != 1 || report.MissingPrerequisites[0].Codes[0] != "CSCI-9999" {
+			t.Errorf("%s: expected 3 courses missing CSCI-9999, got %v courses and %+v", test.format, report.CourseCount, report.MissingPrerequisites)
+		}
+		if report.IssueCount() != 5 {
+			t.Errorf("%s: expected 5 issues, got %+v", test.format, report)
+		}
+
+		var out bytes.Buffer
+		report.WriteText(&out)
+		if !strings.Contains(out.String(), "Duplicate codes (1)\n  line "+strconv.Itoa(test.lines[1])+" CSCI-1100: duplicate course code CSCI-1100") {
+			t.Errorf("%s: expected line numbers in the text report, got %q", test.format, out.String())
+		}
+	}
+}